@@ -0,0 +1,123 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package example contains flag value types defined outside of legacyflag,
+// and the typed legacyflag wrappers generated for them by legacyflag-gen.
+package example
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//go:generate go run sigs.k8s.io/legacyflag/cmd/legacyflag-gen --config config.json
+
+// PortRange is a range of ports, e.g. 30000-32767.
+type PortRange struct {
+	Base int
+	Size int
+}
+
+// String implements pflag.Value
+func (r *PortRange) String() string {
+	if r.Size == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", r.Base, r.Base+r.Size-1)
+}
+
+// Set implements pflag.Value
+func (r *PortRange) Set(value string) error {
+	parts := strings.SplitN(value, "-", 2)
+	low, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid port %q", parts[0])
+	}
+	high := low
+	if len(parts) == 2 {
+		if high, err = strconv.Atoi(parts[1]); err != nil {
+			return fmt.Errorf("invalid port %q", parts[1])
+		}
+	}
+	if high < low {
+		return fmt.Errorf("invalid port range %q", value)
+	}
+	r.Base, r.Size = low, high-low+1
+	return nil
+}
+
+// Type implements pflag.Value
+func (r *PortRange) Type() string {
+	return "portRange"
+}
+
+// Taint is a node taint.
+type Taint struct {
+	Key    string
+	Value  string
+	Effect string
+}
+
+// Taints is a list of node taints, in the format key=value:Effect.
+type Taints []Taint
+
+// String implements pflag.Value
+func (t *Taints) String() string {
+	s := make([]string, 0, len(*t))
+	for _, taint := range *t {
+		s = append(s, fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect))
+	}
+	return strings.Join(s, ",")
+}
+
+// Set implements pflag.Value. Multiple invocations append to the list.
+func (t *Taints) Set(value string) error {
+	for _, s := range strings.Split(value, ",") {
+		kv := strings.SplitN(s, ":", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid taint %q, must be key=value:Effect", s)
+		}
+		pair := strings.SplitN(kv[0], "=", 2)
+		taint := Taint{Key: pair[0], Effect: kv[1]}
+		if len(pair) == 2 {
+			taint.Value = pair[1]
+		}
+		*t = append(*t, taint)
+	}
+	return nil
+}
+
+// Type implements pflag.Value
+func (t *Taints) Type() string {
+	return "taints"
+}
+
+// Merge adds the taints in other, replacing taints with the same key.
+func (t *Taints) Merge(other Taints) {
+	for _, taint := range other {
+		replaced := false
+		for i := range *t {
+			if (*t)[i].Key == taint.Key {
+				(*t)[i] = taint
+				replaced = true
+			}
+		}
+		if !replaced {
+			*t = append(*t, taint)
+		}
+	}
+}
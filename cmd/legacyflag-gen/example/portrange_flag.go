@@ -0,0 +1,57 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by legacyflag-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+// PortRangeValue is a reference to a registered PortRange flag value.
+type PortRangeValue struct {
+	value PortRange
+	ref   *legacyflag.VarValue
+}
+
+// PortRangeVar registers a flag for PortRange against the FlagSet, and returns
+// a PortRangeValue reference to the registered flag value.
+func PortRangeVar(fs *legacyflag.FlagSet, name string, def PortRange, usage string) *PortRangeValue {
+	return PortRangeVarP(fs, name, "", def, usage)
+}
+
+// PortRangeVarP is like PortRangeVar, but accepts a shorthand letter that can be
+// used after a single dash.
+func PortRangeVarP(fs *legacyflag.FlagSet, name, shorthand string, def PortRange, usage string) *PortRangeValue {
+	v := &PortRangeValue{value: def}
+	v.ref = fs.VarP(&v.value, name, shorthand, usage)
+	return v
+}
+
+// Set copies the flag value to the target if the flag was set.
+func (v *PortRangeValue) Set(target *PortRange) {
+	v.ref.Apply(func() {
+		*target = v.value
+	})
+}
+
+// Apply calls the apply func with the flag value if the flag was set.
+func (v *PortRangeValue) Apply(apply func(value PortRange)) {
+	v.ref.Apply(func() {
+		apply(v.value)
+	})
+}
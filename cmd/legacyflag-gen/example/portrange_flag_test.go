@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by legacyflag-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+func TestPortRangeVar(t *testing.T) {
+	cases := []struct {
+		name  string
+		args  []string
+		def   PortRange
+		set   PortRange
+		apply bool
+		err   string
+	}{
+		{
+			name:  "flag is set",
+			args:  []string{"--foo=30000-32767"},
+			set:   PortRange{Base: 30000, Size: 2768},
+			apply: true,
+		},
+		{
+			name:  "flag is set with shorthand",
+			args:  []string{"-f=30000-32767"},
+			set:   PortRange{Base: 30000, Size: 2768},
+			apply: true,
+		},
+		{
+			name:  "flag is set with alias",
+			args:  []string{"--old-foo=30000-32767"},
+			set:   PortRange{Base: 30000, Size: 2768},
+			apply: true,
+		},
+		{
+			name: "flag is not set",
+			args: []string{},
+		},
+		{
+			name: "default is not copied when flag is not set",
+			args: []string{},
+			def:  PortRange{Base: 80, Size: 1},
+		},
+		{
+			name:  "single port",
+			args:  []string{"--foo=8080"},
+			set:   PortRange{Base: 8080, Size: 1},
+			apply: true,
+		},
+		{
+			name:  "repeated flag, last wins",
+			args:  []string{"--foo=80", "--foo=443"},
+			set:   PortRange{Base: 443, Size: 1},
+			apply: true,
+		},
+		{
+			name: "reversed range",
+			args: []string{"--foo=2-1"},
+			err:  "invalid port range \"2-1\"",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var target PortRange
+
+			fs := legacyflag.NewFlagSet("")
+			val := PortRangeVarP(fs, "foo", "f", c.def, "")
+			fs.PflagFlagSet().SetOutput(ioutil.Discard)
+			if err := fs.Alias("old-foo", "foo", ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			val.Set(&target)
+			if !reflect.DeepEqual(target, c.set) {
+				t.Errorf("Set: got %#v but expected %#v", target, c.set)
+			}
+
+			applied := false
+			val.Apply(func(value PortRange) {
+				applied = true
+				// value passed to apply func should match the expected result of Set
+				if !reflect.DeepEqual(value, c.set) {
+					t.Errorf("Apply: got %#v but expected %#v", value, c.set)
+				}
+			})
+			if c.apply && !applied {
+				t.Errorf("Apply: apply func not called")
+			} else if !c.apply && applied {
+				t.Errorf("Apply: apply func called, should not have been")
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by legacyflag-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+// TaintsValue is a reference to a registered Taints flag value.
+type TaintsValue struct {
+	value Taints
+	ref   *legacyflag.VarValue
+}
+
+// TaintsVar registers a flag for Taints against the FlagSet, and returns
+// a TaintsValue reference to the registered flag value.
+func TaintsVar(fs *legacyflag.FlagSet, name string, def Taints, usage string) *TaintsValue {
+	return TaintsVarP(fs, name, "", def, usage)
+}
+
+// TaintsVarP is like TaintsVar, but accepts a shorthand letter that can be
+// used after a single dash.
+func TaintsVarP(fs *legacyflag.FlagSet, name, shorthand string, def Taints, usage string) *TaintsValue {
+	v := &TaintsValue{value: def}
+	v.ref = fs.VarP(&v.value, name, shorthand, usage)
+	return v
+}
+
+// Set copies the flag value to the target if the flag was set.
+func (v *TaintsValue) Set(target *Taints) {
+	v.ref.Apply(func() {
+		*target = v.value
+	})
+}
+
+// Merge merges the flag value into the target with Taints.Merge if
+// the flag was set.
+func (v *TaintsValue) Merge(target *Taints) {
+	v.ref.Apply(func() {
+		target.Merge(v.value)
+	})
+}
+
+// Apply calls the apply func with the flag value if the flag was set.
+func (v *TaintsValue) Apply(apply func(value Taints)) {
+	v.ref.Apply(func() {
+		apply(v.value)
+	})
+}
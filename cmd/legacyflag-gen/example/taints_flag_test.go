@@ -0,0 +1,134 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by legacyflag-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+func TestTaintsVar(t *testing.T) {
+	cases := []struct {
+		name  string
+		args  []string
+		def   Taints
+		set   Taints
+		apply bool
+		err   string
+	}{
+		{
+			name:  "flag is set",
+			args:  []string{"--foo=a=b:NoSchedule,c:NoExecute"},
+			set:   Taints{{Key: "a", Value: "b", Effect: "NoSchedule"}, {Key: "c", Effect: "NoExecute"}},
+			apply: true,
+		},
+		{
+			name:  "flag is set with shorthand",
+			args:  []string{"-f=a=b:NoSchedule,c:NoExecute"},
+			set:   Taints{{Key: "a", Value: "b", Effect: "NoSchedule"}, {Key: "c", Effect: "NoExecute"}},
+			apply: true,
+		},
+		{
+			name:  "flag is set with alias",
+			args:  []string{"--old-foo=a=b:NoSchedule,c:NoExecute"},
+			set:   Taints{{Key: "a", Value: "b", Effect: "NoSchedule"}, {Key: "c", Effect: "NoExecute"}},
+			apply: true,
+		},
+		{
+			name: "flag is not set",
+			args: []string{},
+		},
+		{
+			name: "default is not copied when flag is not set",
+			args: []string{},
+			def:  Taints{{Key: "d", Effect: "NoSchedule"}},
+		},
+		{
+			name:  "repeated flag appends",
+			args:  []string{"--foo=a:NoSchedule", "-f=b:NoExecute"},
+			set:   Taints{{Key: "a", Effect: "NoSchedule"}, {Key: "b", Effect: "NoExecute"}},
+			apply: true,
+		},
+		{
+			name:  "flag appends to default",
+			args:  []string{"--foo=a:NoSchedule"},
+			def:   Taints{{Key: "d", Effect: "NoSchedule"}},
+			set:   Taints{{Key: "d", Effect: "NoSchedule"}, {Key: "a", Effect: "NoSchedule"}},
+			apply: true,
+		},
+		{
+			name: "missing effect",
+			args: []string{"--foo=a=b"},
+			err:  "invalid taint \"a=b\", must be key=value:Effect",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var target Taints
+
+			fs := legacyflag.NewFlagSet("")
+			val := TaintsVarP(fs, "foo", "f", c.def, "")
+			fs.PflagFlagSet().SetOutput(ioutil.Discard)
+			if err := fs.Alias("old-foo", "foo", ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			val.Set(&target)
+			if !reflect.DeepEqual(target, c.set) {
+				t.Errorf("Set: got %#v but expected %#v", target, c.set)
+			}
+
+			var merged Taints
+			val.Merge(&merged)
+			// merging into the zero value should match the expected result of Set
+			if !reflect.DeepEqual(merged, c.set) {
+				t.Errorf("Merge: got %#v but expected %#v", merged, c.set)
+			}
+
+			applied := false
+			val.Apply(func(value Taints) {
+				applied = true
+				// value passed to apply func should match the expected result of Set
+				if !reflect.DeepEqual(value, c.set) {
+					t.Errorf("Apply: got %#v but expected %#v", value, c.set)
+				}
+			})
+			if c.apply && !applied {
+				t.Errorf("Apply: apply func not called")
+			} else if !c.apply && applied {
+				t.Errorf("Apply: apply func called, should not have been")
+			}
+		})
+	}
+}
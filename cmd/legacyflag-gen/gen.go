@@ -0,0 +1,416 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// legacyflagPackage is the import path of the legacyflag package.
+const legacyflagPackage = "sigs.k8s.io/legacyflag/pkg/legacyflag"
+
+// Config is the overall config that drives codegen
+type Config struct {
+	// Package is the import path of the target package where generated Go
+	// files should be created. It must be in the module containing the
+	// working directory.
+	Package string
+	// Types are the types to generate Go files for.
+	Types []TypeConfig
+	// Boilerplate is the path to a file containing the license header for
+	// generated files, relative to the config file. The string YEAR is
+	// replaced with the copyright year. Defaults to the Kubernetes license
+	// header.
+	Boilerplate string
+
+	// boilerplate is the license header template.
+	boilerplate string
+
+	// Absolute path to the package dir. This is where we save generated files.
+	pkgPath string
+	// Name of the package. Used for "package" statement at the top of files.
+	pkgName string
+}
+
+// TypeConfig is the codegen config for a single type
+type TypeConfig struct {
+	// Qualified Go name of the type
+	Type string
+	// Name of the type in pflag, e.g. []int may be named IntSlice.
+	Name string
+	// Import path for the package containing Type
+	ImportPath string
+
+	// Value is set for types that implement pflag.Value with a pointer
+	// receiver, e.g. types defined outside of pflag. Value types get typed
+	// wrappers around legacyflag.VarValue, registered with generated functions
+	// that take the FlagSet, e.g. TaintsVar(fs, name, def, usage). Types
+	// generated into packages other than legacyflag must be Value types.
+	Value bool
+	// Merge is the name of a method with a pointer receiver on a Value type,
+	// with the signature func(other Type). If set, a Merge method that calls
+	// it is generated on the wrapper.
+	Merge string
+
+	// Generated tests:
+	// String flag input to test
+	TestFlagInput string
+	// Raw Go string result of Set operation
+	TestSetResult string
+	// Raw Go string for a non-zero flag default. If set, a test checks that
+	// the default is not copied to the target when the flag is not set.
+	TestDefault string
+	// Additional test cases, e.g. for other inputs, parse errors and repeated
+	// flags.
+	Tests []TestCase
+}
+
+// TestCase is a generated test case for a type. The flag is registered as
+// --foo, with shorthand -f and the alias --old-foo.
+type TestCase struct {
+	// Name describes the case.
+	Name string
+	// Command line arguments, e.g. ["--foo=a", "--foo=b"]
+	Args []string
+	// Raw Go string for the flag default. Defaults to the zero value.
+	Default string
+	// Raw Go string result of Set operation. If empty, the flag is expected
+	// not to be set.
+	Set string
+	// Substring of the expected parse error, if any.
+	Err string
+}
+
+// GoArgs returns the arguments as a Go []string literal.
+func (c TestCase) GoArgs() string {
+	quoted := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		quoted[i] = strconv.Quote(a)
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+// File is a generated file.
+type File struct {
+	// Path is the absolute path of the file.
+	Path string
+	// Content is the gofmt'd content of the file.
+	Content []byte
+}
+
+// Parse parses the file at path into the config.
+func (c *Config) Parse(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, c); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	c.pkgPath, err = packageDir(wd, c.Package)
+	if err != nil {
+		return err
+	}
+	c.pkgName = pkgName(c.Package)
+	c.boilerplate = license
+	if c.Boilerplate != "" {
+		b, err := ioutil.ReadFile(filepath.Join(filepath.Dir(path), c.Boilerplate))
+		if err != nil {
+			return fmt.Errorf("failed to read boilerplate: %v", err)
+		}
+		c.boilerplate = strings.TrimSpace(string(b))
+	}
+	for _, t := range c.Types {
+		if c.Package != legacyflagPackage && !t.Value {
+			return fmt.Errorf("type %s: only Value types can be generated outside of %s", t.Type, legacyflagPackage)
+		}
+		if t.Merge != "" && !t.Value {
+			return fmt.Errorf("type %s: Merge is only supported for Value types", t.Type)
+		}
+		for _, tc := range t.Tests {
+			if tc.Name == "" {
+				return fmt.Errorf("type %s: test case must have a name", t.Type)
+			}
+			if tc.Set != "" && tc.Err != "" {
+				return fmt.Errorf("type %s: test case %q: Set and Err are mutually exclusive", t.Type, tc.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// packageDir returns the directory of the package with the given import
+// path, which must be in the module containing dir.
+func packageDir(dir, pkg string) (string, error) {
+	root, err := moduleRoot(dir)
+	if err != nil {
+		return "", err
+	}
+	mod, err := modulePath(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	if pkg != mod && !strings.HasPrefix(pkg, mod+"/") {
+		return "", fmt.Errorf("package %s is not in module %s", pkg, mod)
+	}
+	return filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(pkg, mod))), nil
+}
+
+// moduleRoot returns the root directory of the module containing dir.
+func moduleRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("go.mod not found, run the generator from within a module")
+		}
+		dir = parent
+	}
+}
+
+// modulePath returns the module path declared in the go.mod file at path.
+func modulePath(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 2 && fields[0] == "module" {
+			if mod, err := strconv.Unquote(fields[1]); err == nil {
+				return mod, nil
+			}
+			return fields[1], nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no module declaration in %s", path)
+}
+
+// pkgName returns the package name for an import path, ignoring major
+// version suffixes.
+func pkgName(importPath string) string {
+	name := path.Base(importPath)
+	if regexp.MustCompile(`^v[0-9]+$`).MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+	return strings.Replace(name, "-", "_", -1)
+}
+
+// Gen generates the files for all types in the Config.
+func (c *Config) Gen() ([]File, error) {
+	files := []File{}
+	for _, t := range c.Types {
+		f, err := c.genType(t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %v", t.Type, err)
+		}
+		files = append(files, f...)
+	}
+	return files, nil
+}
+
+// genType generates the files for the type.
+func (c *Config) genType(t TypeConfig) ([]File, error) {
+	tmpl, testTmpl := basicTmpl, basicTestTmpl
+	switch {
+	case t.Value:
+		tmpl, testTmpl = valueTmpl, valueTestTmpl
+	case strings.HasPrefix(t.Type, "[]"):
+		tmpl = sliceTmpl
+	}
+	f, err := c.gen(t, tmpl, false)
+	if err != nil {
+		return nil, err
+	}
+	test, err := c.gen(t, testTmpl, true)
+	if err != nil {
+		return nil, err
+	}
+	return []File{f, test}, nil
+}
+
+func (c *Config) gen(t TypeConfig, tmpl *template.Template, test bool) (File, error) {
+	out := outPath(c.pkgPath, t, test)
+	year, err := copyrightYear(out)
+	if err != nil {
+		return File{}, err
+	}
+	data := tmplData{
+		PkgName:    c.pkgName,
+		External:   c.Package != legacyflagPackage,
+		TypeConfig: t,
+	}
+	// the type is defined in the target package
+	if t.ImportPath == c.Package {
+		data.ImportPath = ""
+	}
+	b := &bytes.Buffer{}
+	b.WriteString(header(c.boilerplate, year))
+	if err := tmpl.Execute(b, data); err != nil {
+		return File{}, err
+	}
+	content, err := format.Source(b.Bytes())
+	if err != nil {
+		return File{}, fmt.Errorf("failed to format %s: %v", out, err)
+	}
+	return File{Path: out, Content: content}, nil
+}
+
+// outPath returns the path of the file generated for t.
+func outPath(pkgPath string, t TypeConfig, test bool) string {
+	suffix := ".go"
+	if test {
+		suffix = "_test.go"
+	}
+	name := t.Type
+	switch {
+	case t.Value:
+		// avoid clobbering the file that defines the type
+		name = name[strings.LastIndex(name, ".")+1:] + "_flag"
+	case strings.HasPrefix(name, "[]"):
+		name = name[2:] + "_slice"
+	}
+	return filepath.Join(pkgPath, strings.ToLower(strings.Replace(name, ".", "_", -1)+suffix))
+}
+
+// Write writes the generated files.
+func Write(files []File) error {
+	for _, f := range files {
+		if err := ioutil.WriteFile(f.Path, f.Content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stale returns the sorted paths of the generated files that are missing or
+// differ from the files on disk.
+func Stale(files []File) ([]string, error) {
+	stale := []string{}
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err != nil || !bytes.Equal(b, f.Content) {
+			stale = append(stale, f.Path)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+const license = `/*
+Copyright YEAR The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/`
+
+var copyrightRE = regexp.MustCompile(`Copyright ([0-9]{4}) `)
+
+// copyrightYear returns the copyright year of the existing file at path, so
+// that regenerating a file doesn't change its license header, or the current
+// year for new files.
+func copyrightYear(path string) (int, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return time.Now().Year(), nil
+	} else if err != nil {
+		return 0, err
+	}
+	if m := copyrightRE.FindSubmatch(b); m != nil {
+		return strconv.Atoi(string(m[1]))
+	}
+	return time.Now().Year(), nil
+}
+
+const genwarning = "// Code generated by legacyflag-gen. DO NOT EDIT."
+
+// header returns the license header and generated code warning.
+func header(boilerplate string, year int) string {
+	return strings.Replace(boilerplate, "YEAR", strconv.Itoa(year), 1) + "\n\n" + genwarning + "\n\n"
+}
+
+// Q returns the qualifier for legacyflag identifiers in the target package.
+func (d tmplData) Q() string {
+	if d.External {
+		return "legacyflag."
+	}
+	return ""
+}
+
+// Cases returns the generated test cases: the built-in cases for
+// TestFlagInput and TestDefault, followed by the configured cases.
+func (d tmplData) Cases() []TestCase {
+	cases := []TestCase{
+		{Name: "flag is set", Args: []string{"--foo=" + d.TestFlagInput}, Set: d.TestSetResult},
+		{Name: "flag is set with shorthand", Args: []string{"-f=" + d.TestFlagInput}, Set: d.TestSetResult},
+		{Name: "flag is set with alias", Args: []string{"--old-foo=" + d.TestFlagInput}, Set: d.TestSetResult},
+		{Name: "flag is not set", Args: []string{}},
+	}
+	if d.TestDefault != "" {
+		cases = append(cases, TestCase{Name: "default is not copied when flag is not set", Args: []string{}, Default: d.TestDefault})
+	}
+	return append(cases, d.Tests...)
+}
+
+type tmplData struct {
+	PkgName string
+	// External is set if the target package is not legacyflag.
+	External bool
+
+	TypeConfig
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGeneratedFilesUpToDate fails if the checked-in generated files differ
+// from the generator output.
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	for _, config := range []string{"../../hack/gen/config.json", "example/config.json"} {
+		c := &Config{}
+		if err := c.Parse(config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		files, err := c.Gen()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		stale, err := Stale(files)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, path := range stale {
+			t.Errorf("%s is out of date, run legacyflag-gen --config %s", path, config)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	cases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "value type outside legacyflag",
+			config: `{"Package": "sigs.k8s.io/legacyflag/cmd/legacyflag-gen/example", "Types": [{"Type": "PortRange", "Name": "PortRange", "Value": true}]}`,
+		},
+		{
+			name:   "basic type outside legacyflag",
+			config: `{"Package": "sigs.k8s.io/legacyflag/cmd/legacyflag-gen/example", "Types": [{"Type": "string", "Name": "String"}]}`,
+			err:    "type string: only Value types can be generated outside of sigs.k8s.io/legacyflag/pkg/legacyflag",
+		},
+		{
+			name:   "merge without value",
+			config: `{"Package": "sigs.k8s.io/legacyflag/pkg/legacyflag", "Types": [{"Type": "string", "Name": "String", "Merge": "Merge"}]}`,
+			err:    "type string: Merge is only supported for Value types",
+		},
+		{
+			name:   "test case without name",
+			config: `{"Package": "sigs.k8s.io/legacyflag/pkg/legacyflag", "Types": [{"Type": "string", "Name": "String", "Tests": [{"Args": ["--foo=a"], "Set": "\"a\""}]}]}`,
+			err:    "type string: test case must have a name",
+		},
+		{
+			name:   "test case with set and error",
+			config: `{"Package": "sigs.k8s.io/legacyflag/pkg/legacyflag", "Types": [{"Type": "string", "Name": "String", "Tests": [{"Name": "bad", "Set": "\"a\"", "Err": "a"}]}]}`,
+			err:    "type string: test case \"bad\": Set and Err are mutually exclusive",
+		},
+		{
+			name:   "missing boilerplate",
+			config: `{"Package": "sigs.k8s.io/legacyflag/pkg/legacyflag", "Boilerplate": "missing.txt"}`,
+			err:    "failed to read boilerplate",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(dir, "config.json")
+			if err := ioutil.WriteFile(path, []byte(c.config), 0644); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := (&Config{}).Parse(path)
+			if c.err == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if c.err != "" && (err == nil || !strings.HasPrefix(err.Error(), c.err)) {
+				t.Errorf("expected error %q but got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestHeader(t *testing.T) {
+	got := header("// Copyright YEAR Example Authors.", 2019)
+	if expect := "// Copyright 2019 Example Authors.\n\n" + genwarning + "\n\n"; got != expect {
+		t.Errorf("got %q but expected %q", got, expect)
+	}
+}
+
+func TestPackageDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module \"example.com/mod\"\n\ngo 1.12\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sub := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := packageDir(sub, "example.com/mod/pkg/flags")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root, _ := filepath.Abs(dir)
+	if expect := filepath.Join(root, "pkg", "flags"); got != expect {
+		t.Errorf("got %q but expected %q", got, expect)
+	}
+	if _, err := packageDir(sub, "example.com/other"); err == nil {
+		t.Errorf("expected error for package outside the module")
+	}
+}
+
+func TestPkgName(t *testing.T) {
+	for importPath, expect := range map[string]string{
+		"sigs.k8s.io/legacyflag/pkg/legacyflag": "legacyflag",
+		"example.com/mod/v2":                    "mod",
+		"example.com/my-flags":                  "my_flags",
+	} {
+		if got := pkgName(importPath); got != expect {
+			t.Errorf("%s: got %q but expected %q", importPath, got, expect)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// This program generates typed legacyflag value references and their tests
+// from a config file. See Config for the config format.
+//
+// Usage:
+//   legacyflag-gen --config config.json [--verify]
+
+var (
+	configPath = pflag.String("config", "", "path to a json file containing a Config")
+	verify     = pflag.Bool("verify", false, "verify that the generated files are up to date instead of writing them")
+)
+
+func main() {
+	pflag.Parse()
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, pflag.CommandLine.FlagUsages())
+		os.Exit(1)
+	}
+	c := &Config{}
+	if err := c.Parse(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	files, err := c.Gen()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if *verify {
+		stale, err := Stale(files)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		if len(stale) > 0 {
+			fmt.Fprintf(os.Stderr, "Generated files are out of date, run legacyflag-gen --config %s:\n", *configPath)
+			for _, path := range stale {
+				fmt.Fprintf(os.Stderr, "  %s\n", path)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+	if err := Write(files); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
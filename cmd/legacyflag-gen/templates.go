@@ -0,0 +1,381 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"text/template"
+)
+
+// Templates for types in the legacyflag package. These register flags
+// against the underlying pflag.FlagSet, and use its change tracking directly.
+
+var basicTmpl = template.Must(template.New("basic").Parse(basicTmplRaw))
+
+const basicTmplRaw = `package {{.PkgName}}
+
+import (
+{{- if .ImportPath}}
+	"{{.ImportPath}}"
+{{end}}
+	"github.com/spf13/pflag"
+)
+
+// {{.Name}}Value is a reference to a registered {{.Type}} flag value.
+type {{.Name}}Value struct {
+	name string
+	value {{.Type}}
+	fs *pflag.FlagSet
+}
+
+// {{.Name}}Var registers a flag for {{.Type}} against the FlagSet, and returns
+// a {{.Name}}Value reference to the registered flag value.
+func (fs *FlagSet) {{.Name}}Var(name string, def {{.Type}}, usage string) *{{.Name}}Value {
+	return fs.{{.Name}}VarP(name, "", def, usage)
+}
+
+// {{.Name}}VarP is like {{.Name}}Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) {{.Name}}VarP(name, shorthand string, def {{.Type}}, usage string) *{{.Name}}Value {
+	v := &{{.Name}}Value{
+		name: name,
+		fs: fs.fs,
+	}
+	fs.fs.{{.Name}}VarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
+	return v
+}
+
+// Set copies the flag value to the target if the flag was set.
+func (v *{{.Name}}Value) Set(target *{{.Type}}) {
+	if v.fs.Changed(v.name) {
+		*target = v.value
+	}
+}
+
+// Apply calls the apply func with the flag value if the flag was set.
+func (v *{{.Name}}Value) Apply(apply func(value {{.Type}})) {
+	if v.fs.Changed(v.name) {
+		apply(v.value)
+	}
+}
+
+// goType implements goTyper.
+func (v *{{.Name}}Value) goType() string {
+	return "{{.Type}}"
+}
+`
+
+var sliceTmpl = template.Must(template.New("slice").Parse(sliceTmplRaw))
+
+const sliceTmplRaw = `package {{.PkgName}}
+
+import (
+{{- if .ImportPath}}
+	"{{.ImportPath}}"
+{{end}}
+	"github.com/spf13/pflag"
+)
+
+// {{.Name}}Value is a reference to a registered {{.Type}} flag value.
+type {{.Name}}Value struct {
+	name string
+	value {{.Type}}
+	fs *pflag.FlagSet
+}
+
+// {{.Name}}Var registers a flag for {{.Type}} against the FlagSet, and
+// returns a {{.Name}}Value reference to the registered flag value.
+func (fs *FlagSet) {{.Name}}Var(name string, def {{.Type}}, usage string) *{{.Name}}Value {
+	return fs.{{.Name}}VarP(name, "", def, usage)
+}
+
+// {{.Name}}VarP is like {{.Name}}Var, but accepts a shorthand letter that can
+// be used after a single dash.
+func (fs *FlagSet) {{.Name}}VarP(name, shorthand string, def {{.Type}}, usage string) *{{.Name}}Value {
+	v := &{{.Name}}Value{
+		name: name,
+		fs: fs.fs,
+	}
+	fs.fs.{{.Name}}VarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
+	return v
+}
+
+// Set copies the flag value to the target if the flag was set.
+func (v *{{.Name}}Value) Set(target *{{.Type}}) {
+	if v.fs.Changed(v.name) {
+		*target = make({{.Type}}, len(v.value))
+		copy(*target, v.value)
+	}
+}
+
+// Apply calls the apply func with the flag value if the flag was set.
+func (v *{{.Name}}Value) Apply(apply func(value {{.Type}})) {
+	if v.fs.Changed(v.name) {
+		apply(v.value)
+	}
+}
+
+// goType implements goTyper.
+func (v *{{.Name}}Value) goType() string {
+	return "{{.Type}}"
+}
+`
+
+var basicTestTmpl = template.Must(template.New("basic_test").Parse(basicTestTmplRaw))
+
+const basicTestTmplRaw = `package {{.PkgName}}
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+{{- if .ImportPath}}
+	"{{.ImportPath}}"
+{{- end}}
+)
+
+func Test{{.Name}}Var(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		def   {{.Type}}
+		set   {{.Type}}
+		apply bool
+		err   string
+	}{
+{{- range .Cases}}
+		{
+			name: {{printf "%q" .Name}},
+			args: {{.GoArgs}},
+{{- if .Default}}
+			def: {{.Default}},
+{{- end}}
+{{- if .Set}}
+			set: {{.Set}},
+			apply: true,
+{{- end}}
+{{- if .Err}}
+			err: {{printf "%q" .Err}},
+{{- end}}
+		},
+{{- end}}
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var target {{.Type}}
+
+			fs := NewFlagSet("")
+			val := fs.{{.Name}}VarP("foo", "f", c.def, "")
+			fs.PflagFlagSet().SetOutput(ioutil.Discard)
+			if err := fs.Alias("old-foo", "foo", ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			val.Set(&target)
+			if !reflect.DeepEqual(target, c.set) {
+				t.Errorf("Set: got %#v but expected %#v", target, c.set)
+			}
+
+			applied := false
+			val.Apply(func(value {{.Type}}) {
+				applied = true
+				// value passed to apply func should match the expected result of Set
+				if !reflect.DeepEqual(value, c.set) {
+					t.Errorf("Apply: got %#v but expected %#v", value, c.set)
+				}
+			})
+			if c.apply && !applied {
+				t.Errorf("Apply: apply func not called")
+			} else if !c.apply && applied {
+				t.Errorf("Apply: apply func called, should not have been")
+			}
+		})
+	}
+}
+`
+
+// Templates for Value types, which may be generated into any package. These
+// wrap a legacyflag.VarValue, which tracks whether the flag was set.
+
+var valueTmpl = template.Must(template.New("value").Parse(valueTmplRaw))
+
+const valueTmplRaw = `package {{.PkgName}}
+
+import (
+{{- if .ImportPath}}
+	"{{.ImportPath}}"
+{{end}}
+{{- if .External}}
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+{{- end}}
+)
+
+// {{.Name}}Value is a reference to a registered {{.Type}} flag value.
+type {{.Name}}Value struct {
+	value {{.Type}}
+	ref *{{.Q}}VarValue
+}
+
+// {{.Name}}Var registers a flag for {{.Type}} against the FlagSet, and returns
+// a {{.Name}}Value reference to the registered flag value.
+func {{.Name}}Var(fs *{{.Q}}FlagSet, name string, def {{.Type}}, usage string) *{{.Name}}Value {
+	return {{.Name}}VarP(fs, name, "", def, usage)
+}
+
+// {{.Name}}VarP is like {{.Name}}Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func {{.Name}}VarP(fs *{{.Q}}FlagSet, name, shorthand string, def {{.Type}}, usage string) *{{.Name}}Value {
+	v := &{{.Name}}Value{value: def}
+	v.ref = fs.VarP(&v.value, name, shorthand, usage)
+	return v
+}
+
+// Set copies the flag value to the target if the flag was set.
+func (v *{{.Name}}Value) Set(target *{{.Type}}) {
+	v.ref.Apply(func() {
+		*target = v.value
+	})
+}
+{{- if .Merge}}
+
+// Merge merges the flag value into the target with {{.Type}}.{{.Merge}} if
+// the flag was set.
+func (v *{{.Name}}Value) Merge(target *{{.Type}}) {
+	v.ref.Apply(func() {
+		target.{{.Merge}}(v.value)
+	})
+}
+{{- end}}
+
+// Apply calls the apply func with the flag value if the flag was set.
+func (v *{{.Name}}Value) Apply(apply func(value {{.Type}})) {
+	v.ref.Apply(func() {
+		apply(v.value)
+	})
+}
+`
+
+var valueTestTmpl = template.Must(template.New("value_test").Parse(valueTestTmplRaw))
+
+const valueTestTmplRaw = `package {{.PkgName}}
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+{{- if .ImportPath}}
+	"{{.ImportPath}}"
+{{- end}}
+{{- if .External}}
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+{{- end}}
+)
+
+func Test{{.Name}}Var(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		def   {{.Type}}
+		set   {{.Type}}
+		apply bool
+		err   string
+	}{
+{{- range .Cases}}
+		{
+			name: {{printf "%q" .Name}},
+			args: {{.GoArgs}},
+{{- if .Default}}
+			def: {{.Default}},
+{{- end}}
+{{- if .Set}}
+			set: {{.Set}},
+			apply: true,
+{{- end}}
+{{- if .Err}}
+			err: {{printf "%q" .Err}},
+{{- end}}
+		},
+{{- end}}
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var target {{.Type}}
+
+			fs := {{.Q}}NewFlagSet("")
+			val := {{.Name}}VarP(fs, "foo", "f", c.def, "")
+			fs.PflagFlagSet().SetOutput(ioutil.Discard)
+			if err := fs.Alias("old-foo", "foo", ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			val.Set(&target)
+			if !reflect.DeepEqual(target, c.set) {
+				t.Errorf("Set: got %#v but expected %#v", target, c.set)
+			}
+{{- if .Merge}}
+
+			var merged {{.Type}}
+			val.Merge(&merged)
+			// merging into the zero value should match the expected result of Set
+			if !reflect.DeepEqual(merged, c.set) {
+				t.Errorf("Merge: got %#v but expected %#v", merged, c.set)
+			}
+{{- end}}
+
+			applied := false
+			val.Apply(func(value {{.Type}}) {
+				applied = true
+				// value passed to apply func should match the expected result of Set
+				if !reflect.DeepEqual(value, c.set) {
+					t.Errorf("Apply: got %#v but expected %#v", value, c.set)
+				}
+			})
+			if c.apply && !applied {
+				t.Errorf("Apply: apply func not called")
+			} else if !c.apply && applied {
+				t.Errorf("Apply: apply func called, should not have been")
+			}
+		})
+	}
+}
+`
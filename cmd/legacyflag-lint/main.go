@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+	"sigs.k8s.io/legacyflag/pkg/legacyflag/lint"
+)
+
+// This program validates a captured command line against the flag schema
+// exported by a component's legacyflag.FlagSet (see FlagSet.Schema).
+//
+// Usage:
+//   legacyflag-lint --schema schema.json -- --flag=value ...
+//   legacyflag-lint --schema schema.json --manifest pod.yaml [--container name]
+
+var (
+	schemaPath   = pflag.String("schema", "", "path to a JSON flag schema exported with FlagSet.Schema")
+	manifestPath = pflag.String("manifest", "", "path to a Kubernetes manifest to read the command line from, instead of the arguments after --")
+	container    = pflag.String("container", "", "name of the container in --manifest, required if the manifest contains more than one container")
+)
+
+func main() {
+	pflag.Parse()
+	if *schemaPath == "" {
+		fmt.Fprintln(os.Stderr, pflag.CommandLine.FlagUsages())
+		os.Exit(2)
+	}
+	findings, err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(2)
+	}
+	failed := false
+	for _, f := range findings {
+		fmt.Println(f)
+		if f.Severity == lint.Error {
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func run() ([]lint.Finding, error) {
+	b, err := ioutil.ReadFile(*schemaPath)
+	if err != nil {
+		return nil, err
+	}
+	s := &legacyflag.Schema{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("failed to decode schema: %v", err)
+	}
+
+	args := pflag.Args()
+	if *manifestPath != "" {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("--manifest cannot be combined with a command line")
+		}
+		b, err := ioutil.ReadFile(*manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		if args, err = lint.ArgsFromManifest(b, *container); err != nil {
+			return nil, err
+		}
+	}
+	return lint.Lint(s, args)
+}
@@ -1,9 +1,15 @@
 module sigs.k8s.io/legacyflag
 
-go 1.12
+go 1.18
 
 // Below require/replace pin the same versions used by k/k.
 
-require github.com/spf13/pflag v1.0.1
+require (
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/spf13/cobra v0.0.3
+	github.com/spf13/pflag v1.0.1
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	sigs.k8s.io/yaml v1.1.0
+)
 
 replace github.com/spf13/pflag => github.com/spf13/pflag v1.0.1
@@ -0,0 +1,154 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// AliasPolicy determines how a flag that was set using more than one of its
+// names is handled. See Alias.
+type AliasPolicy int
+
+const (
+	// AliasLastWins applies the values in command line order, as if they were
+	// all set using the canonical name, so the last one wins.
+	AliasLastWins AliasPolicy = iota
+	// AliasConflictError makes Parse return an error.
+	AliasConflictError
+)
+
+// alias is an old name for a flag, registered by Alias.
+type alias struct {
+	old, new string
+	// resolved is set once the alias was resolved in the current Parse.
+	resolved bool
+	// marked is set if the canonical flag was marked as set because the
+	// alias was set, as opposed to being set by name.
+	marked bool
+}
+
+// Alias registers oldName as an alias of the existing flag newName, e.g.
+// after renaming a flag. Both names set the same value, and the value reports
+// the flag as set if either name was used. The alias is marked deprecated
+// with deprecationMsg, or a default message if it is empty, so it is listed
+// as deprecated in usage and warns when used.
+func (fs *FlagSet) Alias(oldName, newName, deprecationMsg string) error {
+	f := fs.fs.Lookup(newName)
+	if f == nil {
+		return fmt.Errorf("flag %q does not exist", newName)
+	}
+	if fs.fs.Lookup(oldName) != nil {
+		return fmt.Errorf("flag %q already exists", oldName)
+	}
+	if deprecationMsg == "" {
+		deprecationMsg = deprecated
+	}
+	// the alias shares the Value, but pflag tracks whether it was set
+	// separately
+	a := *f
+	a.Name = oldName
+	a.Shorthand = ""
+	a.ShorthandDeprecated = ""
+	a.Deprecated = deprecationMsg
+	a.Annotations = nil
+	fs.fs.AddFlag(&a)
+	fs.register(oldName, nil)
+	fs.aliases = append(fs.aliases, &alias{old: fs.canonical(oldName), new: f.Name})
+	return nil
+}
+
+// SetAliasPolicy sets how a flag that was set using more than one of its
+// names is handled. The default is AliasLastWins.
+func (fs *FlagSet) SetAliasPolicy(p AliasPolicy) {
+	fs.aliasPolicy = p
+}
+
+// resolveAliases marks the flags that were set using an alias as set, and
+// returns an error if the alias policy rejects setting a flag using more
+// than one of its names.
+func (fs *FlagSet) resolveAliases() error {
+	if fs.aliasPolicy == AliasConflictError {
+		// the names that were used for each flag, in registration order
+		used := map[string][]string{}
+		order := []string{}
+		for _, a := range fs.aliases {
+			if a.resolved || !fs.fs.Changed(a.old) {
+				continue
+			}
+			if _, ok := used[a.new]; !ok {
+				order = append(order, a.new)
+				if fs.fs.Changed(a.new) && !fs.markedByAlias(a.new) {
+					used[a.new] = []string{a.new}
+				}
+			}
+			used[a.new] = append(used[a.new], a.old)
+		}
+		for _, name := range order {
+			if names := used[name]; len(names) > 1 {
+				return fmt.Errorf("flags --%s refer to the same flag, but were set together", strings.Join(names, ", --"))
+			}
+		}
+	}
+	for _, a := range fs.aliases {
+		if a.resolved || !fs.fs.Changed(a.old) {
+			continue
+		}
+		if !fs.fs.Changed(a.new) {
+			if err := fs.markSet(a.new); err != nil {
+				return err
+			}
+			a.marked = true
+		}
+		a.resolved = true
+	}
+	return nil
+}
+
+// markedByAlias returns true if the named flag was marked as set because one
+// of its aliases was set.
+func (fs *FlagSet) markedByAlias(name string) bool {
+	for _, a := range fs.aliases {
+		if a.new == name && a.marked {
+			return true
+		}
+	}
+	return false
+}
+
+// markSet marks the named flag as set through pflag, so that it is also
+// visited by Visit and counted by NFlag, without setting its value again,
+// which would e.g. append to a slice twice.
+func (fs *FlagSet) markSet(name string) error {
+	f := fs.fs.Lookup(name)
+	v, msg := f.Value, f.Deprecated
+	// the alias already warned if it was used
+	f.Value, f.Deprecated = noopValue{v}, ""
+	defer func() { f.Value, f.Deprecated = v, msg }()
+	return fs.fs.Set(name, v.String())
+}
+
+// noopValue is a pflag.Value that ignores Set.
+type noopValue struct {
+	pflag.Value
+}
+
+// Set implements pflag.Value
+func (noopValue) Set(string) error { return nil }
@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestAlias(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy AliasPolicy
+		args   []string
+
+		// expect
+		set      string
+		apply    bool
+		slice    []string
+		visited  []string
+		warnings string
+		err      string
+	}{
+		{
+			name: "not set",
+			args: []string{},
+		},
+		{
+			name:    "canonical name",
+			args:    []string{"--foo=a"},
+			set:     "a",
+			apply:   true,
+			visited: []string{"foo"},
+		},
+		{
+			name:     "old name",
+			args:     []string{"--experimental-foo=a"},
+			set:      "a",
+			apply:    true,
+			visited:  []string{"experimental-foo", "foo"},
+			warnings: "Flag --experimental-foo has been deprecated, use --foo instead\n",
+		},
+		{
+			name:     "both names, last wins",
+			args:     []string{"--foo=a", "--experimental-foo=b"},
+			set:      "b",
+			apply:    true,
+			visited:  []string{"experimental-foo", "foo"},
+			warnings: "Flag --experimental-foo has been deprecated, use --foo instead\n",
+		},
+		{
+			name:     "slice values accumulate",
+			args:     []string{"--list=a", "--old-list=b,c"},
+			slice:    []string{"a", "b", "c"},
+			visited:  []string{"list", "old-list"},
+			warnings: "Flag --old-list has been deprecated, " + deprecated + "\n",
+		},
+		{
+			name:   "both names, conflict",
+			policy: AliasConflictError,
+			args:   []string{"--experimental-foo=b", "--foo=a"},
+			err:    "flags --foo, --experimental-foo refer to the same flag, but were set together",
+		},
+		{
+			name:     "old name, conflict policy",
+			policy:   AliasConflictError,
+			args:     []string{"--experimental-foo=b"},
+			set:      "b",
+			apply:    true,
+			visited:  []string{"experimental-foo", "foo"},
+			warnings: "Flag --experimental-foo has been deprecated, use --foo instead\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := NewFlagSet("")
+			out := &bytes.Buffer{}
+			fs.PflagFlagSet().SetOutput(out)
+			fs.SetAliasPolicy(c.policy)
+			v := fs.StringVar("foo", "", "")
+			list := fs.StringSliceVar("list", nil, "")
+			if err := fs.Alias("experimental-foo", "foo", "use --foo instead"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := fs.Alias("old-list", "list", ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("expected error %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			target := ""
+			v.Set(&target)
+			if target != c.set {
+				t.Errorf("Set: got %q but expected %q", target, c.set)
+			}
+			apply := false
+			v.Apply(func(string) { apply = true })
+			if apply != c.apply {
+				t.Errorf("Apply: got %t but expected %t", apply, c.apply)
+			}
+			var slice []string
+			list.Set(&slice)
+			if !reflect.DeepEqual(slice, c.slice) {
+				t.Errorf("Set: got %q but expected %q", slice, c.slice)
+			}
+			var visited []string
+			fs.PflagFlagSet().Visit(func(f *pflag.Flag) { visited = append(visited, f.Name) })
+			if !reflect.DeepEqual(visited, c.visited) {
+				t.Errorf("Visit: got %q but expected %q", visited, c.visited)
+			}
+			if n := fs.PflagFlagSet().NFlag(); n != len(c.visited) {
+				t.Errorf("NFlag: got %d but expected %d", n, len(c.visited))
+			}
+			if out.String() != c.warnings {
+				t.Errorf("warnings: got %q but expected %q", out.String(), c.warnings)
+			}
+			// validating again must not report a conflict
+			if err := fs.Validate(); err != nil {
+				t.Errorf("unexpected error from Validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestAliasErrors(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.StringVar("foo", "", "")
+	fs.StringVar("bar", "", "")
+	if err := fs.Alias("old", "missing", ""); err == nil || err.Error() != "flag \"missing\" does not exist" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := fs.Alias("bar", "foo", ""); err == nil || err.Error() != "flag \"bar\" already exists" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAliasUsage(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.StringVar("foo", "", "The foo.")
+	if err := fs.Alias("experimental-foo", "foo", "use --foo instead"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := &bytes.Buffer{}
+	fs.Usage(out, 0)
+	if !strings.Contains(out.String(), "--experimental-foo string   The foo. (DEPRECATED: use --foo instead)") {
+		t.Errorf("expected the alias to be listed as deprecated, got:\n%s", out.String())
+	}
+}
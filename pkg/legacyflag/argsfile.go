@@ -0,0 +1,157 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Args files let components keep very long command lines in versioned files.
+// An argument of the form @path is replaced by the arguments read from path:
+//  - Each line holds exactly one argument; leading and trailing whitespace
+//    is trimmed.
+//  - Blank lines and lines starting with # are ignored.
+//  - A line wrapped in double quotes is unquoted with Go string syntax, and a
+//    line wrapped in single quotes is taken literally. Quoting preserves
+//    surrounding whitespace and lets an argument start with # or @.
+//  - A line of the form @path includes another args file. Relative paths are
+//    resolved against the directory of the including file.
+// Expansion stops at the first "--" argument, so positional arguments are
+// never treated as args files.
+
+// expandArgsFiles replaces @path arguments with the contents of the
+// referenced args files.
+func expandArgsFiles(args []string) ([]string, error) {
+	e := &argsFileExpander{}
+	for _, arg := range args {
+		if err := e.expand(arg, "", nil); err != nil {
+			return nil, err
+		}
+	}
+	return e.out, nil
+}
+
+type argsFileExpander struct {
+	out []string
+	// done is set once "--" has been seen.
+	done bool
+}
+
+// expand appends arg to the output, recursively expanding it if it refers to
+// an args file. dir is the directory relative paths are resolved against, and
+// stack contains the absolute paths of the files currently being expanded.
+func (e *argsFileExpander) expand(arg, dir string, stack []string) error {
+	if e.done || len(arg) < 2 || arg[0] != '@' {
+		e.add(arg)
+		return nil
+	}
+	path := arg[1:]
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve args file %q: %v", path, err)
+	}
+	for i, p := range stack {
+		if p == abs {
+			return fmt.Errorf("args file include cycle: %s", strings.Join(append(stack[i:], abs), " -> "))
+		}
+	}
+	lines, err := readArgsFile(abs)
+	if err != nil {
+		return err
+	}
+	stack = append(stack, abs)
+	for _, l := range lines {
+		if l.literal {
+			e.add(l.arg)
+			continue
+		}
+		if err := e.expand(l.arg, filepath.Dir(abs), stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add appends arg to the output without expanding it.
+func (e *argsFileExpander) add(arg string) {
+	if arg == "--" {
+		e.done = true
+	}
+	e.out = append(e.out, arg)
+}
+
+// argsFileLine is a single argument read from an args file.
+type argsFileLine struct {
+	arg string
+	// literal is true if the argument was quoted, and must not be expanded.
+	literal bool
+}
+
+// readArgsFile reads the arguments from the args file at path.
+func readArgsFile(path string) ([]argsFileLine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open args file: %v", err)
+	}
+	defer f.Close()
+
+	lines := []argsFileLine{}
+	s := bufio.NewScanner(f)
+	// allow long arguments, e.g. large comma-separated lists
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		l, err := parseArgsFileLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+		lines = append(lines, l)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read args file %s: %v", path, err)
+	}
+	return lines, nil
+}
+
+// parseArgsFileLine parses a trimmed, non-empty, non-comment line.
+func parseArgsFileLine(line string) (argsFileLine, error) {
+	switch line[0] {
+	case '"':
+		arg, err := strconv.Unquote(line)
+		if err != nil {
+			return argsFileLine{}, fmt.Errorf("invalid double-quoted argument %s", line)
+		}
+		return argsFileLine{arg: arg, literal: true}, nil
+	case '\'':
+		if len(line) < 2 || line[len(line)-1] != '\'' {
+			return argsFileLine{}, fmt.Errorf("unterminated single-quoted argument %s", line)
+		}
+		return argsFileLine{arg: line[1 : len(line)-1], literal: true}, nil
+	}
+	return argsFileLine{arg: line}, nil
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandArgsFiles(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		args  []string
+
+		// expect
+		expand []string
+		err    string
+	}{
+		{
+			name:   "no args files",
+			args:   []string{"--foo=bar", "baz"},
+			expand: []string{"--foo=bar", "baz"},
+		},
+		{
+			name: "one arg per line",
+			files: map[string]string{
+				"args": "--foo=bar\n  --baz=a b  \n\n",
+			},
+			args:   []string{"--first", "@args", "--last"},
+			expand: []string{"--first", "--foo=bar", "--baz=a b", "--last"},
+		},
+		{
+			name: "comments",
+			files: map[string]string{
+				"args": "# leading comment\n--foo=bar\n   # indented comment\n",
+			},
+			args:   []string{"@args"},
+			expand: []string{"--foo=bar"},
+		},
+		{
+			name: "quoting",
+			files: map[string]string{
+				"args": "\"  padded \\\"arg\\\"  \"\n'# not a comment'\n'@not-a-file'\n\"\"\n",
+			},
+			args:   []string{"@args"},
+			expand: []string{"  padded \"arg\"  ", "# not a comment", "@not-a-file", ""},
+		},
+		{
+			name: "nested includes resolve relative to the including file",
+			files: map[string]string{
+				"args":          "--foo=bar\n@nested/args\n--baz=quux",
+				"nested/args":   "--one\n@more",
+				"nested/more":   "--two",
+				"unrelated/any": "--never",
+			},
+			args:   []string{"@args"},
+			expand: []string{"--foo=bar", "--one", "--two", "--baz=quux"},
+		},
+		{
+			name: "same file included twice is not a cycle",
+			files: map[string]string{
+				"args":   "@common\n@common",
+				"common": "--foo",
+			},
+			args:   []string{"@args"},
+			expand: []string{"--foo", "--foo"},
+		},
+		{
+			name: "no expansion after --",
+			files: map[string]string{
+				"args": "--foo\n--\n@args",
+			},
+			args:   []string{"@args", "@args"},
+			expand: []string{"--foo", "--", "@args", "@args"},
+		},
+		{
+			name:   "lone @ is not an args file",
+			args:   []string{"@"},
+			expand: []string{"@"},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"a": "@b",
+				"b": "@a",
+			},
+			args: []string{"@a"},
+			err:  "args file include cycle",
+		},
+		{
+			name: "missing file",
+			args: []string{"@missing"},
+			err:  "failed to open args file",
+		},
+		{
+			name: "unterminated quote",
+			files: map[string]string{
+				"args": "--foo\n'bar",
+			},
+			args: []string{"@args"},
+			err:  "args:2: unterminated single-quoted argument 'bar",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "argsfile")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			for name, content := range c.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			args := make([]string, len(c.args))
+			for i, arg := range c.args {
+				if strings.HasPrefix(arg, "@") && len(arg) > 1 {
+					arg = "@" + filepath.Join(dir, arg[1:])
+				}
+				args[i] = arg
+			}
+
+			expand, err := expandArgsFiles(args)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expect error containing %q but got %v", c.err, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, arg := range expand {
+				expand[i] = strings.Replace(arg, dir+string(filepath.Separator), "", 1)
+			}
+			if !reflect.DeepEqual(expand, c.expand) {
+				t.Errorf("got %#v but expected %#v", expand, c.expand)
+			}
+		})
+	}
+}
+
+func TestParseArgsFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "argsfile")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "args")
+	if err := ioutil.WriteFile(path, []byte("--foo=fromfile\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// without expansion, the args file is a positional argument
+	fs := NewFlagSet("")
+	val := fs.StringVar("foo", "", "")
+	if err := fs.Parse([]string{"@" + path}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args := fs.PflagFlagSet().Args(); !reflect.DeepEqual(args, []string{"@" + path}) {
+		t.Errorf("got args %#v but expected %#v", args, []string{"@" + path})
+	}
+
+	fs = NewFlagSet("")
+	fs.SetExpandArgsFiles(true)
+	val = fs.StringVar("foo", "", "")
+	if err := fs.Parse([]string{"@" + path}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var target string
+	val.Set(&target)
+	if target != "fromfile" {
+		t.Errorf("got %q but expected %q", target, "fromfile")
+	}
+}
@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,36 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
+import (
 	"github.com/spf13/pflag"
-	
 )
 
 // BoolSliceValue is a reference to a registered []bool flag value.
 type BoolSliceValue struct {
-	name string
+	name  string
 	value []bool
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // BoolSliceVar registers a flag for []bool against the FlagSet, and
 // returns a BoolSliceValue reference to the registered flag value.
 func (fs *FlagSet) BoolSliceVar(name string, def []bool, usage string) *BoolSliceValue {
+	return fs.BoolSliceVarP(name, "", def, usage)
+}
+
+// BoolSliceVarP is like BoolSliceVar, but accepts a shorthand letter that can
+// be used after a single dash.
+func (fs *FlagSet) BoolSliceVarP(name, shorthand string, def []bool, usage string) *BoolSliceValue {
 	v := &BoolSliceValue{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.BoolSliceVar(&v.value, name, def, usage)
+	fs.fs.BoolSliceVarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -55,3 +61,8 @@ func (v *BoolSliceValue) Apply(apply func(value []bool)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *BoolSliceValue) goType() string {
+	return "[]bool"
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cobra integrates a legacyflag.FlagSet with a cobra.Command.
+package cobra
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+// Options control how a FlagSet is attached to a command.
+type Options struct {
+	// Persistent attaches the flags as persistent flags, which are inherited
+	// by subcommands. Otherwise, the flags are local to the command.
+	Persistent bool
+
+	// PersistentFlags lists the flags that are attached as persistent flags
+	// if Persistent is false, e.g. global flags shared by all subcommands.
+	// The other flags are local to the command. Names that are not flags of
+	// the FlagSet are ignored.
+	PersistentFlags []string
+
+	// Load loads the config file, e.g. the file named by a --config flag.
+	// It is called after the flags were parsed and validated. Optional.
+	Load func() error
+
+	// Apply applies the flag values on top of the loaded config, e.g. with
+	// the Set, Merge and Apply methods of the registered flag values. It is
+	// called after Load. Optional.
+	Apply func() error
+
+	// UsageWidth is the column at which flag usage lines are wrapped. No
+	// wrapping if 0.
+	UsageWidth int
+}
+
+// Attach adds the flags of fs to cmd. Before the command runs, Attach
+// validates the flag values, then calls Load and Apply, from PreRunE, or from
+// PersistentPreRunE if any flags are persistent. Existing pre-run hooks of cmd
+// are called first. Attach also renders the usage of cmd with the sections of
+// fs.
+//
+// Cobra only runs the PersistentPreRunE of the closest command that has one.
+// If a subcommand sets its own PersistentPreRun or PersistentPreRunE, the
+// hook attached to its parent does not run for it, so the flags are neither
+// validated nor applied, unless the subcommand's hook calls the parent's,
+// e.g. cmd.Parent().PersistentPreRunE(cmd, args).
+func Attach(cmd *cobra.Command, fs *legacyflag.FlagSet, o Options) {
+	persistent := map[string]bool{}
+	for _, name := range o.PersistentFlags {
+		persistent[name] = true
+	}
+	fs.PflagFlagSet().VisitAll(func(f *pflag.Flag) {
+		flags := cmd.Flags()
+		if o.Persistent || persistent[f.Name] {
+			flags = cmd.PersistentFlags()
+		}
+		// like pflag.FlagSet.AddFlagSet, skip flags that already exist
+		if flags.Lookup(f.Name) == nil {
+			flags.AddFlag(f)
+		}
+	})
+	if o.Persistent || len(persistent) > 0 {
+		cmd.PersistentPreRunE = preRunE(cmd.PersistentPreRunE, cmd.PersistentPreRun, fs, &o)
+		cmd.PersistentPreRun = nil
+	} else {
+		cmd.PreRunE = preRunE(cmd.PreRunE, cmd.PreRun, fs, &o)
+		cmd.PreRun = nil
+	}
+	cmd.SetUsageFunc(usageFunc(fs, o.UsageWidth))
+}
+
+// preRunE returns a pre-run hook that calls the existing hooks, if any, then
+// validates fs and calls o.Load and o.Apply.
+func preRunE(existingE func(*cobra.Command, []string) error, existing func(*cobra.Command, []string),
+	fs *legacyflag.FlagSet, o *Options) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if existingE != nil {
+			if err := existingE(cmd, args); err != nil {
+				return err
+			}
+		} else if existing != nil {
+			existing(cmd, args)
+		}
+		if err := fs.Validate(); err != nil {
+			return err
+		}
+		if o.Load != nil {
+			if err := o.Load(); err != nil {
+				return fmt.Errorf("failed to load config: %v", err)
+			}
+		}
+		if o.Apply != nil {
+			if err := o.Apply(); err != nil {
+				return fmt.Errorf("failed to apply flags: %v", err)
+			}
+		}
+		return nil
+	}
+}
+
+// usageTemplate is the default cobra usage template, with the local flags
+// rendered in the sections of the attached FlagSet.
+const usageTemplate = `Usage:{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}{{if gt (len .Aliases) 0}}
+
+Aliases:
+  {{.NameAndAliases}}{{end}}{{if .HasExample}}
+
+Examples:
+{{.Example}}{{end}}{{if .HasAvailableSubCommands}}
+
+Available Commands:{{range .Commands}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
+
+{{sectionedFlagUsages .LocalFlags | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableInheritedFlags}}
+
+Global Flags:
+{{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasHelpSubCommands}}
+
+Additional help topics:{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
+  {{rpad .CommandPath .CommandPathPadding}} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableSubCommands}}
+
+Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}
+`
+
+// usageFunc returns a cobra usage function that renders usageTemplate.
+func usageFunc(fs *legacyflag.FlagSet, width int) func(*cobra.Command) error {
+	t := template.Must(template.New("usage").Funcs(template.FuncMap{
+		"rpad": func(s string, padding int) string {
+			return fmt.Sprintf(fmt.Sprintf("%%-%ds", padding), s)
+		},
+		"trimTrailingWhitespaces": func(s string) string {
+			return strings.TrimRightFunc(s, unicode.IsSpace)
+		},
+		"sectionedFlagUsages": func(flags *pflag.FlagSet) (string, error) {
+			b := &bytes.Buffer{}
+			err := fs.UsageFor(b, width, flags)
+			return b.String(), err
+		},
+	}).Parse(usageTemplate))
+	return func(cmd *cobra.Command) error {
+		return t.Execute(cmd.OutOrStderr(), cmd)
+	}
+}
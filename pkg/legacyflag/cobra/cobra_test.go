@@ -0,0 +1,248 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cobra
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+type config struct {
+	Port int
+	Name string
+}
+
+func TestAttach(t *testing.T) {
+	cases := []struct {
+		name            string
+		persistent      bool
+		persistentFlags []string
+		args            []string
+
+		// expect
+		config config
+		calls  []string
+		err    string
+	}{
+		{
+			name:   "flags override config",
+			args:   []string{"--port=443"},
+			config: config{Port: 443, Name: "fromfile"},
+			calls:  []string{"prerun", "load", "apply", "run"},
+		},
+		{
+			name:   "flags not set",
+			args:   []string{},
+			config: config{Port: 80, Name: "fromfile"},
+			calls:  []string{"prerun", "load", "apply", "run"},
+		},
+		{
+			name:   "persistent flags in subcommand",
+			args:   []string{"sub", "--name=fromflag"},
+			config: config{Port: 80, Name: "fromflag"},
+			calls:  []string{"prerun", "load", "apply", "sub"},
+
+			persistent: true,
+		},
+		{
+			name:   "persistent and local flags",
+			args:   []string{"--port=443"},
+			config: config{Port: 443, Name: "fromfile"},
+			calls:  []string{"prerun", "load", "apply", "run"},
+
+			persistentFlags: []string{"name"},
+		},
+		{
+			name:   "persistent flag in subcommand",
+			args:   []string{"sub", "--name=fromflag"},
+			config: config{Port: 80, Name: "fromflag"},
+			calls:  []string{"prerun", "load", "apply", "sub"},
+
+			persistentFlags: []string{"name"},
+		},
+		{
+			name:  "local flag in subcommand",
+			args:  []string{"sub", "--port=443"},
+			calls: []string{},
+			err:   "unknown flag: --port",
+
+			persistentFlags: []string{"name"},
+		},
+		{
+			name:  "invalid flag",
+			args:  []string{"--port=443", "--name=fromflag"},
+			calls: []string{"prerun"},
+			err:   `flags --port, --name are mutually exclusive, but were set together`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls := []string{}
+			cfg := config{}
+
+			fs := legacyflag.NewFlagSet("test")
+			port := fs.IntVar("port", 0, "The port.")
+			name := fs.StringVar("name", "", "The name.")
+			if err := fs.MarkMutuallyExclusive("port", "name"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			run := func(name string) func(*cobra.Command, []string) {
+				return func(*cobra.Command, []string) { calls = append(calls, name) }
+			}
+			cmd := &cobra.Command{Use: "test", Run: run("run")}
+			cmd.AddCommand(&cobra.Command{Use: "sub", Run: run("sub")})
+			if c.persistent || len(c.persistentFlags) > 0 {
+				cmd.PersistentPreRun = run("prerun")
+			} else {
+				cmd.PreRun = run("prerun")
+			}
+			Attach(cmd, fs, Options{
+				Persistent:      c.persistent,
+				PersistentFlags: c.persistentFlags,
+				Load: func() error {
+					calls = append(calls, "load")
+					cfg = config{Port: 80, Name: "fromfile"}
+					return nil
+				},
+				Apply: func() error {
+					calls = append(calls, "apply")
+					port.Set(&cfg.Port)
+					name.Set(&cfg.Name)
+					return nil
+				},
+			})
+			cmd.SetArgs(c.args)
+			cmd.SetOutput(ioutil.Discard)
+
+			err := cmd.Execute()
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("expect error %s but got %v", c.err, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if !reflect.DeepEqual(cfg, c.config) {
+				t.Errorf("got config %#v but expected %#v", cfg, c.config)
+			}
+			if !reflect.DeepEqual(calls, c.calls) {
+				t.Errorf("got calls %v but expected %v", calls, c.calls)
+			}
+		})
+	}
+}
+
+func TestAttachSubcommandPersistentPreRun(t *testing.T) {
+	cases := []struct {
+		name       string
+		callParent bool
+		calls      []string
+	}{
+		{
+			name:  "subcommand hook replaces the attached hook",
+			calls: []string{"sub prerun", "sub"},
+		},
+		{
+			name:       "subcommand hook calls the attached hook",
+			callParent: true,
+			calls:      []string{"sub prerun", "apply", "sub"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls := []string{}
+			fs := legacyflag.NewFlagSet("test")
+			fs.StringVar("name", "", "The name.")
+
+			cmd := &cobra.Command{Use: "test", Run: func(*cobra.Command, []string) {}}
+			sub := &cobra.Command{
+				Use: "sub",
+				PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+					calls = append(calls, "sub prerun")
+					if c.callParent {
+						return cmd.Parent().PersistentPreRunE(cmd, args)
+					}
+					return nil
+				},
+				Run: func(*cobra.Command, []string) { calls = append(calls, "sub") },
+			}
+			cmd.AddCommand(sub)
+			Attach(cmd, fs, Options{
+				Persistent: true,
+				Apply: func() error {
+					calls = append(calls, "apply")
+					return nil
+				},
+			})
+			cmd.SetArgs([]string{"sub", "--name=fromflag"})
+			cmd.SetOutput(ioutil.Discard)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(calls, c.calls) {
+				t.Errorf("got calls %v but expected %v", calls, c.calls)
+			}
+		})
+	}
+}
+
+func TestUsage(t *testing.T) {
+	fs := legacyflag.NewFlagSet("test")
+	fs.StringVar("name", "", "The name.")
+	fs.Section("Networking").IntVar("port", 80, "The port.")
+	if err := fs.MarkConfigField("port", "Port"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cmd := &cobra.Command{Use: "test", Run: func(*cobra.Command, []string) {}}
+	cmd.PersistentFlags().Bool("verbose", false, "Verbose output.")
+	sub := &cobra.Command{Use: "sub", Short: "A subcommand.", Run: func(*cobra.Command, []string) {}}
+	cmd.AddCommand(sub)
+	Attach(sub, fs, Options{})
+
+	expect := `A subcommand.
+
+Usage:
+  test sub [flags]
+
+Flags:
+  -h, --help          help for sub
+      --name string   The name.
+
+Networking:
+      --port int   The port. (config file field: Port) (default 80)
+
+Global Flags:
+      --verbose   Verbose output.
+`
+	b := &bytes.Buffer{}
+	cmd.SetOutput(b)
+	cmd.SetArgs([]string{"sub", "--help"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.String() != expect {
+		t.Errorf("got:\n%s\nexpected:\n%s", b.String(), expect)
+	}
+}
@@ -0,0 +1,446 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// completion describes how to complete a single flag.
+type completion struct {
+	flag *pflag.Flag
+	// values are the candidates for the flag value. Empty if there are none.
+	values []string
+	// pairSep is set for map flags, whose values are lists of pairs.
+	pairSep string
+	// keyValueSep is set for bool map flags without known keys, whose values
+	// are completed with true or false once the key is typed.
+	keyValueSep string
+	// noSpace is true if the candidates are incomplete, e.g. map keys
+	// followed by the key-value separator.
+	noSpace bool
+	// files is true if the flag value is a file path. extensions optionally
+	// restricts the candidates.
+	files      bool
+	extensions []string
+}
+
+// optionalValue returns true if the flag may be set without a value, e.g. bool
+// flags. Such flags only take a value via --flag=value.
+func (c *completion) optionalValue() bool {
+	return c.flag.NoOptDefVal != ""
+}
+
+// completions returns the completions for the flags that are shown in help,
+// sorted by name.
+func (fs *FlagSet) completions() []*completion {
+	cs := []*completion{}
+	fs.fs.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Deprecated != "" {
+			return
+		}
+		m := fs.Metadata(f.Name)
+		c := &completion{
+			flag:       f,
+			files:      m.Filename,
+			extensions: m.FileExtensions,
+		}
+		switch {
+		case len(m.Enum) > 0:
+			c.values = m.Enum
+		case f.Value.Type() == "bool":
+			c.values = []string{"true", "false"}
+		case len(m.Keys) > 0, f.Value.Type() == "mapStringBool":
+			o := mapOptions(f.Value)
+			c.pairSep = o.PairSep
+			if o.DisableCommaSeparatedPairs {
+				c.pairSep = ""
+			}
+			if len(m.Keys) == 0 {
+				c.keyValueSep = o.KeyValueSep
+			}
+			for _, k := range m.Keys {
+				if f.Value.Type() == "mapStringBool" {
+					c.values = append(c.values, k+o.KeyValueSep+"true", k+o.KeyValueSep+"false")
+				} else {
+					c.values = append(c.values, k+o.KeyValueSep)
+					c.noSpace = true
+				}
+			}
+		}
+		cs = append(cs, c)
+	})
+	return cs
+}
+
+// funcName returns name with characters that are not valid in shell function
+// names replaced by underscores.
+func funcName(name string) string {
+	return regexp.MustCompile(`[^a-zA-Z0-9_]`).ReplaceAllString(name, "_")
+}
+
+// GenBashCompletion writes a bash completion script for the program to w.
+func (fs *FlagSet) GenBashCompletion(w io.Writer) error {
+	name := fs.programName()
+	fn := funcName(name)
+	cs := fs.completions()
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "# bash completion for %s, generated by legacyflag.\n", name)
+	fmt.Fprintf(b, bashHelpers, fn)
+	fmt.Fprintf(b, "\n_%s()\n{\n", fn)
+	b.WriteString(bashPrelude)
+
+	// flags that take their value from the next word
+	for _, c := range cs {
+		if !c.optionalValue() && bashAction(fn, c) != "" {
+			fmt.Fprintf(b, "            %s)\n                flag=\"${prev}\"\n                ;;\n", bashPatterns(c.flag))
+		}
+	}
+	b.WriteString("        esac\n    fi\n\n")
+
+	b.WriteString("    case \"${flag}\" in\n")
+	for _, c := range cs {
+		if action := bashAction(fn, c); action != "" {
+			fmt.Fprintf(b, "        %s)\n            %s\n            ;;\n", bashPatterns(c.flag), action)
+		}
+	}
+	words := []string{}
+	for _, c := range cs {
+		words = append(words, "--"+c.flag.Name)
+		if c.flag.Shorthand != "" && c.flag.ShorthandDeprecated == "" {
+			words = append(words, "-"+c.flag.Shorthand)
+		}
+	}
+	fmt.Fprintf(b, "        \"\")\n            if [[ \"${cur}\" == -* ]]; then\n                __%s_words \"\" %s\n            fi\n            ;;\n", fn, strings.Join(words, " "))
+	b.WriteString("    esac\n")
+	fmt.Fprintf(b, "    __%s_ltrim\n}\n\ncomplete -o default -F _%s %s\n", fn, fn, name)
+
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// bashPatterns returns the case patterns that match the flag, in the forms
+// that can precede a value being completed.
+func bashPatterns(f *pflag.Flag) string {
+	p := []string{"--" + f.Name}
+	if f.Shorthand != "" {
+		p = append(p, "-"+f.Shorthand)
+	}
+	return strings.Join(p, "|")
+}
+
+// bashAction returns the bash command that completes the value of the flag,
+// or the empty string if the value can't be completed.
+func bashAction(fn string, c *completion) string {
+	switch {
+	case c.files:
+		return fmt.Sprintf("__%s_files \"${prefix}\" %s", fn, strings.Join(c.extensions, " "))
+	case c.keyValueSep != "":
+		return fmt.Sprintf("__%s_bools \"${prefix}\" %s %s", fn, bashQuote(c.pairSep), bashQuote(c.keyValueSep))
+	case len(c.values) > 0 && c.pairSep != "":
+		action := fmt.Sprintf("__%s_pairs \"${prefix}\" %s %s", fn, bashQuote(c.pairSep), bashWords(c.values))
+		if c.noSpace {
+			action += "; compopt -o nospace"
+		}
+		return action
+	case len(c.values) > 0:
+		action := fmt.Sprintf("__%s_words \"${prefix}\" %s", fn, bashWords(c.values))
+		if c.noSpace {
+			action += "; compopt -o nospace"
+		}
+		return action
+	}
+	return ""
+}
+
+// bashWords quotes each word for bash.
+func bashWords(words []string) string {
+	q := make([]string, len(words))
+	for i, w := range words {
+		q[i] = bashQuote(w)
+	}
+	return strings.Join(q, " ")
+}
+
+// bashQuote single-quotes s for bash.
+func bashQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// bashHelpers are the helper functions of the bash completion script. %[1]s is
+// the sanitized program name.
+const bashHelpers = `
+# __%[1]s_words prefix words...: completes ${value} from words.
+__%[1]s_words()
+{
+    local prefix="$1"
+    shift
+    local IFS=$'\n'
+    COMPREPLY=( $(compgen -P "${prefix}" -W "$(printf '%%s\n' "$@")" -- "${value}") )
+}
+
+# __%[1]s_pairs prefix sep words...: completes the last of the pairs separated
+# by sep in ${value} from words.
+__%[1]s_pairs()
+{
+    local prefix="$1" sep="$2" pairs=""
+    shift 2
+    if [[ "${value}" == *"${sep}"* ]]; then
+        pairs="${value%%"${sep}"*}${sep}"
+    fi
+    local value="${value#"${pairs}"}"
+    __%[1]s_words "${prefix}${pairs}" "$@"
+}
+
+# __%[1]s_bools prefix sep kvsep: completes true or false as the value of the
+# last of the pairs separated by sep in ${value}, once its key followed by
+# kvsep is typed.
+__%[1]s_bools()
+{
+    local prefix="$1" sep="$2" kvsep="$3" pairs=""
+    if [[ -n "${sep}" && "${value}" == *"${sep}"* ]]; then
+        pairs="${value%%"${sep}"*}${sep}"
+    fi
+    local pair="${value#"${pairs}"}"
+    if [[ "${pair}" != *"${kvsep}"* ]]; then
+        COMPREPLY=()
+        return
+    fi
+    local key="${pair%%%%"${kvsep}"*}${kvsep}"
+    local value="${pair#"${key}"}"
+    __%[1]s_words "${prefix}${pairs}${key}" true false
+}
+
+# __%[1]s_files prefix extensions...: completes file paths, restricted to
+# files with the given extensions, if any.
+__%[1]s_files()
+{
+    local prefix="$1" f ext
+    shift
+    local IFS=$'\n'
+    COMPREPLY=()
+    for f in $(compgen -f -- "${value}"); do
+        if [[ $# -eq 0 || -d "${f}" ]]; then
+            COMPREPLY+=( "${prefix}${f}" )
+            continue
+        fi
+        for ext in "$@"; do
+            if [[ "${f}" == *."${ext}" ]]; then
+                COMPREPLY+=( "${prefix}${f}" )
+            fi
+        done
+    done
+    compopt -o filenames
+}
+
+# __%[1]s_ltrim removes the part of the replies that precedes the last word
+# break character in ${cur}, since bash only replaces the text after it.
+__%[1]s_ltrim()
+{
+    local i
+    for (( i=${#cur}-1; i>=0; i-- )); do
+        if [[ "${COMP_WORDBREAKS}" == *"${cur:i:1}"* ]]; then
+            COMPREPLY=( "${COMPREPLY[@]#"${cur:0:i+1}"}" )
+            return
+        fi
+    done
+}
+`
+
+// bashPrelude determines the flag whose value is being completed, if any.
+// --flag=value words are split at word break characters in COMP_WORDS, so the
+// current and previous words are recovered from COMP_LINE instead.
+const bashPrelude = `    local line="${COMP_LINE:0:${COMP_POINT}}"
+    local cur="${line##*[[:space:]]}"
+    local before="${line%"${cur}"}"
+    before="${before%"${before##*[![:space:]]}"}"
+    local prev="${before##*[[:space:]]}"
+    local flag="" value="${cur}" prefix=""
+    if [[ "${cur}" == -*=* ]]; then
+        flag="${cur%%=*}"
+        value="${cur#*=}"
+        prefix="${flag}="
+    elif [[ "${cur}" != -* ]]; then
+        case "${prev}" in
+`
+
+// GenZshCompletion writes a zsh completion script for the program to w.
+func (fs *FlagSet) GenZshCompletion(w io.Writer) error {
+	name := fs.programName()
+	b := &bytes.Buffer{}
+	fn := funcName(name)
+	fmt.Fprintf(b, "#compdef %s\n\n# zsh completion for %s, generated by legacyflag.\n", name, name)
+	fmt.Fprintf(b, zshHelpers, fn)
+	fmt.Fprintf(b, "\n_%s() {\n  _arguments \\\n", fn)
+	for _, c := range fs.completions() {
+		desc := ""
+		if c.flag.Usage != "" {
+			desc = "[" + zshEscape(c.flag.Usage) + "]"
+		}
+		arg := zshArg(fn, c)
+		long := "--" + c.flag.Name
+		if c.flag.Shorthand != "" && c.flag.ShorthandDeprecated == "" {
+			short := "-" + c.flag.Shorthand
+			// the long and short forms exclude each other
+			exclude := fmt.Sprintf("(%s %s)", long, short)
+			if c.optionalValue() {
+				fmt.Fprintf(b, "    %s \\\n", zshQuote(exclude+short+desc))
+			} else {
+				fmt.Fprintf(b, "    %s \\\n", zshQuote(exclude+short+"+"+desc+arg))
+			}
+			long = exclude + long
+		}
+		if c.optionalValue() {
+			long += "=-"
+		} else {
+			long += "="
+		}
+		fmt.Fprintf(b, "    %s \\\n", zshQuote(long+desc+arg))
+	}
+	fmt.Fprintf(b, "    '*:file:_files'\n}\n\n_%s \"$@\"\n", fn)
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// zshHelpers are the shell functions used by the zsh completion script.
+const zshHelpers = `
+# __%[1]s_bools kvsep: completes true or false after the key and kvsep.
+__%[1]s_bools() {
+  compset -P "*${(b)1}" && compadd - true false
+}
+`
+
+// zshArg returns the _arguments action that completes the flag value.
+func zshArg(fn string, c *completion) string {
+	t := zshEscape(c.flag.Value.Type())
+	switch {
+	case c.files && len(c.extensions) > 0:
+		return fmt.Sprintf(":%s:_files -g \"*.(%s)\"", t, strings.Join(c.extensions, "|"))
+	case c.files:
+		return fmt.Sprintf(":%s:_files", t)
+	case c.keyValueSep != "" && c.pairSep != "":
+		return fmt.Sprintf(":%s:_sequence -s %s __%s_bools %s", t, zshWord(c.pairSep), fn, zshWord(c.keyValueSep))
+	case c.keyValueSep != "":
+		return fmt.Sprintf(":%s:__%s_bools %s", t, fn, zshWord(c.keyValueSep))
+	case len(c.values) > 0 && c.pairSep != "":
+		suffix := ""
+		if c.noSpace {
+			suffix = ` -S ""`
+		}
+		return fmt.Sprintf(":%s:_sequence -s %s compadd%s - %s", t, zshWord(c.pairSep), suffix, strings.Join(zshValues(c.values), " "))
+	case len(c.values) > 0 && c.noSpace:
+		return fmt.Sprintf(`:%s:compadd -S "" - %s`, t, strings.Join(zshValues(c.values), " "))
+	case len(c.values) > 0:
+		return fmt.Sprintf(":%s:(%s)", t, strings.Join(zshValues(c.values), " "))
+	}
+	return fmt.Sprintf(":%s: ", t)
+}
+
+// zshValues escapes values for use in an _arguments action.
+func zshValues(values []string) []string {
+	e := make([]string, len(values))
+	for i, v := range values {
+		e[i] = zshEscape(strings.Replace(v, " ", `\ `, -1))
+	}
+	return e
+}
+
+// zshWord quotes s as a single word of an _arguments action, which zsh runs
+// as shell code.
+func zshWord(s string) string {
+	return zshEscape(zshQuote(s))
+}
+
+// zshEscape escapes characters with special meaning in _arguments specs.
+func zshEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, ":", `\:`, "\n", " ").Replace(s)
+}
+
+// zshQuote single-quotes s for zsh.
+func zshQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// GenFishCompletion writes a fish completion script for the program to w.
+// Since fish has no notion of optional flag values, the values of flags that
+// may be set without a value, e.g. bool flags, are not completed.
+func (fs *FlagSet) GenFishCompletion(w io.Writer) error {
+	name := fs.programName()
+	b := &bytes.Buffer{}
+	fn := funcName(name)
+	fmt.Fprintf(b, "# fish completion for %s, generated by legacyflag.\n", name)
+	fmt.Fprintf(b, fishHelpers, fn)
+	b.WriteString("\n")
+	for _, c := range fs.completions() {
+		fmt.Fprintf(b, "complete -c %s -l %s", fishQuote(name), fishQuote(c.flag.Name))
+		if c.flag.Shorthand != "" && c.flag.ShorthandDeprecated == "" {
+			fmt.Fprintf(b, " -s %s", fishQuote(c.flag.Shorthand))
+		}
+		if c.flag.Usage != "" {
+			fmt.Fprintf(b, " -d %s", fishQuote(strings.Replace(c.flag.Usage, "\n", " ", -1)))
+		}
+		switch {
+		case c.optionalValue():
+		case c.files && len(c.extensions) > 0:
+			args := []string{}
+			for _, ext := range c.extensions {
+				args = append(args, fmt.Sprintf("(__fish_complete_suffix .%s)", ext))
+			}
+			fmt.Fprintf(b, " -r -a %s", fishQuote(strings.Join(args, " ")))
+		case c.files:
+			b.WriteString(" -r")
+		case c.keyValueSep != "":
+			fmt.Fprintf(b, " -x -a %s", fishQuote(fmt.Sprintf("(__%s_bools %s %s)", fn, fishQuote(c.pairSep), fishQuote(c.keyValueSep))))
+		case len(c.values) > 0:
+			fmt.Fprintf(b, " -x -a %s", fishQuote(strings.Join(c.values, " ")))
+		default:
+			b.WriteString(" -x")
+		}
+		b.WriteString("\n")
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// fishHelpers are the shell functions used by the fish completion script.
+const fishHelpers = `
+# __%[1]s_bools sep kvsep: prints the flag value being completed with true or
+# false as the value of its last pair, once its key followed by kvsep is typed.
+function __%[1]s_bools -a sep kvsep
+    set -l value (string replace -r -- '^-[^=]*=' '' (commandline -ct))
+    set -l pairs ''
+    if test -n "$sep"
+        set pairs (string match -r -- '^.*'(string escape --style=regex -- $sep) $value)
+    end
+    set -l pair (string sub -s (math (string length -- "$pairs") + 1) -- $value)
+    set -l key (string match -r -- '^.*?'(string escape --style=regex -- $kvsep) $pair)
+    test -n "$key"; or return
+    printf '%%s\n' $pairs$key'true' $pairs$key'false'
+end
+`
+
+// fishQuote single-quotes s for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func TestGenCompletion(t *testing.T) {
+	fs := NewFlagSet("kubelet")
+	fs.IntVar("port", 10250, "The port to serve on.")
+	fs.BoolVar("debug", false, "Enable [debug] mode.")
+	fs.StringVar("mode", "a", "The mode: a or b.")
+	fs.StringVar("config", "", "Path to the config file.")
+	fs.StringVar("kubeconfig", "", "Path to a kubeconfig file.")
+	fs.StringVar("old", "", "A deprecated flag.")
+	fs.StringVar("secret", "", "A hidden flag.")
+	fs.MapStringBoolVar("feature-gates", nil, "Feature gates.", &MapOptions{})
+	fs.MapStringBoolVar("gates", nil, "Unknown gates.", &MapOptions{PairSep: ";"})
+	fs.MapStringStringVar("node-labels", nil, "Node labels.", &MapOptions{})
+	fs.MapStringStringVar("annotations", nil, "Unknown keys.", &MapOptions{})
+	fs.fs.Lookup("port").Shorthand = "p"
+	fs.fs.Lookup("debug").Shorthand = "d"
+	for _, err := range []error{
+		fs.MarkEnum("mode", "a", "b"),
+		fs.MarkFilename("config", "yaml", "json"),
+		fs.MarkFilename("kubeconfig"),
+		fs.MarkMapKeys("feature-gates", "Foo", "Bar"),
+		fs.MarkMapKeys("node-labels", "zone", "rack"),
+		fs.MarkDeprecated("old", "Use --mode instead."),
+		fs.fs.MarkHidden("secret"),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		golden string
+		gen    func(fs *FlagSet, w io.Writer) error
+		// shell that can check the syntax of the generated script
+		shell string
+	}{
+		{"bash", "completion.bash", (*FlagSet).GenBashCompletion, "bash"},
+		{"zsh", "completion.zsh", (*FlagSet).GenZshCompletion, "zsh"},
+		{"fish", "completion.fish", (*FlagSet).GenFishCompletion, "fish"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := c.gen(fs, b); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			golden := filepath.Join("testdata", c.golden)
+			if *update {
+				if err := ioutil.WriteFile(golden, b.Bytes(), 0644); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			expect, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(b.Bytes(), expect) {
+				t.Errorf("got:\n%s\nexpected:\n%s", b.String(), expect)
+			}
+
+			if _, err := exec.LookPath(c.shell); err != nil {
+				return
+			}
+			if out, err := exec.Command(c.shell, "-n", golden).CombinedOutput(); err != nil {
+				t.Errorf("%s -n %s: %v\n%s", c.shell, golden, err, out)
+			}
+		})
+	}
+}
+
+func TestMarkCompletion(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.StringVar("foo", "", "")
+	fs.MapStringStringVar("bar", nil, "", &MapOptions{})
+	if err := fs.MarkMapKeys("foo", "a"); err == nil {
+		t.Errorf("expect error for keys of non-map flag")
+	}
+	if err := fs.MarkMapKeys("missing", "a"); err == nil {
+		t.Errorf("expect error for unknown flag")
+	}
+	if err := fs.MarkFilename("missing"); err == nil {
+		t.Errorf("expect error for unknown flag")
+	}
+	if err := fs.MarkMapKeys("bar", "a", "b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.MarkFilename("foo", "yaml"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m := fs.Metadata("bar"); len(m.Keys) != 2 {
+		t.Errorf("got keys %v", m.Keys)
+	}
+	if m := fs.Metadata("foo"); !m.Filename || len(m.FileExtensions) != 1 {
+		t.Errorf("got filename %t, extensions %v", m.Filename, m.FileExtensions)
+	}
+}
@@ -0,0 +1,32 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+type testAuthConfig struct {
+	Anonymous struct {
+		Enabled bool
+	}
+}
+
+type testConfig struct {
+	Name   string
+	Port   int32
+	Labels map[string]string
+	List   listValue
+	Custom testValue
+	Auth   *testAuthConfig
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the values of sensitive flags, see MarkSensitive.
+const Redacted = "<redacted>"
+
+// Diff returns the config fields that the flags were applied to, with their
+// values in before, the config before the flags were applied, e.g. the
+// default config or the config loaded from the config file, and in after, the
+// effective config. before and after must be pointers to the same
+// ComponentConfig struct type.
+//
+// A field is listed for every flag with a ConfigField that was set, even if
+// the flag did not change its value, sorted by flag name. The values of
+// sensitive flags are Redacted. The result is suitable for logging which
+// flags changed the config at startup.
+func (fs *FlagSet) Diff(before, after interface{}) ([]FieldChange, error) {
+	old, err := configRoot(before)
+	if err != nil {
+		return nil, err
+	}
+	new, err := configRoot(after)
+	if err != nil {
+		return nil, err
+	}
+	if old.Type() != new.Type() {
+		return nil, fmt.Errorf("configs must have the same type, got %T and %T", before, after)
+	}
+	changes := []FieldChange{}
+	for _, name := range fs.configFlags() {
+		if !fs.fs.Changed(name) {
+			continue
+		}
+		m := fs.meta[name]
+		c := FieldChange{Field: m.ConfigField, Flag: name}
+		if c.Old, err = lookupConfigField(old, m.ConfigField); err != nil {
+			return nil, fmt.Errorf("flag --%s: %v", name, err)
+		}
+		if c.New, err = lookupConfigField(new, m.ConfigField); err != nil {
+			return nil, fmt.Errorf("flag --%s: %v", name, err)
+		}
+		if m.Sensitive {
+			c.Old, c.New = Redacted, Redacted
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// configFlags returns the sorted names of the flags with a ConfigField.
+func (fs *FlagSet) configFlags() []string {
+	names := []string{}
+	for name, m := range fs.meta {
+		if m.ConfigField != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupConfigField returns the value of the field of the struct v named by
+// the dot separated path. Nil struct pointers along the path are treated as
+// pointers to the zero value.
+func lookupConfigField(v reflect.Value, path string) (interface{}, error) {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v = reflect.Zero(v.Type().Elem())
+			} else {
+				v = v.Elem()
+			}
+		}
+		if v.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("config field %s: %s is not a struct", path, v.Type())
+		}
+		f, ok := v.Type().FieldByName(name)
+		if !ok || f.PkgPath != "" {
+			return nil, fmt.Errorf("config field %s: %s has no exported field %s", path, v.Type(), name)
+		}
+		v = v.FieldByIndex(f.Index)
+	}
+	return v.Interface(), nil
+}
+
+// sensitiveField returns true if the config field, or a field it contains,
+// is set by a sensitive flag.
+func (fs *FlagSet) sensitiveField(field string) bool {
+	for _, m := range fs.meta {
+		if m.Sensitive && m.ConfigField != "" && (m.ConfigField == field || strings.HasPrefix(m.ConfigField, field+".")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	fs := NewFlagSet("")
+	name := fs.StringVar("name", "", "")
+	port := fs.Int32Var("port", 0, "")
+	labels := fs.MapStringStringVar("labels", nil, "", &MapOptions{})
+	anonymous := fs.BoolVar("anonymous-auth", false, "")
+	fs.Var(&testValue{}, "custom", "")
+	for flag, field := range map[string]string{
+		"name":           "Name",
+		"port":           "Port",
+		"labels":         "Labels",
+		"anonymous-auth": "Auth.Anonymous.Enabled",
+		"custom":         "Custom",
+	} {
+		if err := fs.MarkConfigField(flag, field); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := fs.MarkSensitive("name"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Parse([]string{"--name=secret", "--port=80", "--labels=a=b", "--anonymous-auth"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	before := &testConfig{Name: "file", Port: 80, Custom: testValue{s: "file"}}
+	after := &testConfig{Name: "file", Port: 80, Custom: testValue{s: "file"}}
+	name.Set(&after.Name)
+	port.Set(&after.Port)
+	labels.Set(&after.Labels)
+	anonymous.Apply(func(enabled bool) {
+		after.Auth = &testAuthConfig{}
+		after.Auth.Anonymous.Enabled = enabled
+	})
+
+	changes, err := fs.Diff(before, after)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect := []FieldChange{
+		{Field: "Auth.Anonymous.Enabled", Old: false, New: true, Flag: "anonymous-auth"},
+		{Field: "Labels", Old: map[string]string(nil), New: map[string]string{"a": "b"}, Flag: "labels"},
+		{Field: "Name", Old: Redacted, New: Redacted, Flag: "name"},
+		{Field: "Port", Old: int32(80), New: int32(80), Flag: "port"},
+	}
+	if !reflect.DeepEqual(changes, expect) {
+		t.Errorf("got %#v but expected %#v", changes, expect)
+	}
+
+	strs := []string{}
+	for _, c := range changes {
+		strs = append(strs, c.String())
+	}
+	expectStrs := []string{
+		"Auth.Anonymous.Enabled: false -> true (--anonymous-auth)",
+		"Labels: map[] -> map[a:b] (--labels)",
+		"Name: <redacted> -> <redacted> (--name)",
+		"Port: 80 -> 80 (--port)",
+	}
+	if !reflect.DeepEqual(strs, expectStrs) {
+		t.Errorf("got %q but expected %q", strs, expectStrs)
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.StringVar("name", "", "")
+	if err := fs.MarkConfigField("name", "Name"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Parse([]string{"--name=a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cases := []struct {
+		name          string
+		before, after interface{}
+		err           string
+	}{
+		{
+			name:   "different types",
+			before: &testConfig{},
+			after:  &testAuthConfig{},
+			err:    "configs must have the same type, got *legacyflag.testConfig and *legacyflag.testAuthConfig",
+		},
+		{
+			name:   "not a pointer",
+			before: testConfig{},
+			after:  &testConfig{},
+			err:    "config must be a non-nil pointer to a struct, got legacyflag.testConfig",
+		},
+		{
+			name:   "config field does not exist",
+			before: &testAuthConfig{},
+			after:  &testAuthConfig{},
+			err:    "flag --name: config field Name: legacyflag.testAuthConfig has no exported field Name",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := fs.Diff(c.before, c.after)
+			if err == nil || err.Error() != c.err {
+				t.Errorf("expected error %q but got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestReloaderRedactsSensitiveFields(t *testing.T) {
+	fs := NewFlagSet("")
+	anonymous := fs.BoolVar("anonymous-auth", false, "")
+	if err := fs.MarkConfigField("anonymous-auth", "Auth.Anonymous.Enabled"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.MarkSensitive("anonymous-auth"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r, err := fs.NewReloader(&testConfig{}, func(cfg interface{}) error {
+		anonymous.Apply(func(enabled bool) {
+			c := cfg.(*testConfig)
+			c.Auth = &testAuthConfig{}
+			c.Auth.Anonymous.Enabled = enabled
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changes, err := r.Reload(&testConfig{Name: "a", Auth: &testAuthConfig{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect := []FieldChange{
+		{Field: "Name", Old: "", New: "a"},
+		{Field: "Auth", Old: Redacted, New: Redacted},
+	}
+	if !reflect.DeepEqual(changes, expect) {
+		t.Errorf("got %#v but expected %#v", changes, expect)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package docs renders reference documentation for a legacyflag.FlagSet.
+package docs
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+// Flag is the documentation for a single flag.
+type Flag struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default"`
+	Usage      string `json:"usage"`
+	Deprecated string `json:"deprecated,omitempty"`
+	Hidden     bool   `json:"hidden,omitempty"`
+
+	// ConfigField is the ComponentConfig field the flag maps to, if any.
+	ConfigField string `json:"configField,omitempty"`
+	// Section is the usage section the flag is listed in, if any.
+	Section string `json:"section,omitempty"`
+	// Visibility is the visibility level of the flag, omitted for public
+	// flags.
+	Visibility legacyflag.Visibility `json:"visibility,omitempty"`
+
+	// Validation constraints.
+	Enum []string `json:"enum,omitempty"`
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+}
+
+// Flags returns the documentation for all flags in fs, sorted by name.
+// Internal flags are not documented.
+func Flags(fs *legacyflag.FlagSet) []Flag {
+	flags := []Flag{}
+	fs.PflagFlagSet().VisitAll(func(f *pflag.Flag) {
+		m := fs.Metadata(f.Name)
+		if m.Visibility == legacyflag.VisibilityInternal {
+			return
+		}
+		flags = append(flags, Flag{
+			Name:        f.Name,
+			Shorthand:   f.Shorthand,
+			Type:        f.Value.Type(),
+			Default:     f.DefValue,
+			Usage:       f.Usage,
+			Deprecated:  f.Deprecated,
+			Hidden:      f.Hidden && f.Deprecated == "",
+			ConfigField: m.ConfigField,
+			Section:     m.Section,
+			Visibility:  m.Visibility,
+			Enum:        m.Enum,
+			Min:         m.Min,
+			Max:         m.Max,
+		})
+	})
+	return flags
+}
+
+// JSON writes the documentation for all flags in fs as a JSON array.
+func JSON(w io.Writer, fs *legacyflag.FlagSet) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Flags(fs))
+}
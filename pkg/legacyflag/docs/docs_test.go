@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func TestRender(t *testing.T) {
+	fs := legacyflag.NewFlagSet("test")
+	fs.IntVar("port", 10250, "The port to serve on.")
+	fs.StringVar("mode", "a", "The mode | operation.")
+	fs.BoolVar("old", false, "An old flag.")
+	fs.BoolVar("secret", false, "A hidden flag.")
+	fs.WithVisibility(legacyflag.VisibilityExperimental).BoolVar("new-thing", false, "An experimental flag.")
+	fs.WithVisibility(legacyflag.VisibilityInternal).BoolVar("testing-only", false, "An internal flag.")
+	fs.PflagFlagSet().Lookup("port").Shorthand = "p"
+	for _, err := range []error{
+		fs.MarkConfigField("port", "Port"),
+		fs.MarkRange("port", 1, 65535),
+		fs.MarkEnum("mode", "a", "b"),
+		fs.MarkDeprecated("old", "Use --new instead."),
+		fs.MarkHidden("secret"),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		golden string
+		render func(w io.Writer, fs *legacyflag.FlagSet) error
+	}{
+		{"json", "flags.json", JSON},
+		{"markdown", "flags.md", Markdown},
+		{"man", "flags.1", func(w io.Writer, fs *legacyflag.FlagSet) error {
+			return Man(w, fs, ManHeader{
+				Title:  "TEST",
+				Date:   "Jan 2019",
+				Source: "Kubernetes",
+				Manual: `Kubernetes "User" Manuals`,
+			})
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := c.render(b, fs); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			golden := filepath.Join("testdata", c.golden)
+			if *update {
+				if err := ioutil.WriteFile(golden, b.Bytes(), 0644); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			expect, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(b.Bytes(), expect) {
+				t.Errorf("got:\n%s\nexpected:\n%s", b.String(), expect)
+			}
+		})
+	}
+}
+
+func TestRoffLine(t *testing.T) {
+	cases := []struct {
+		name   string
+		line   string
+		expect string
+	}{
+		{"plain", "plain text", "plain text"},
+		{"control character", ".foo", `\&.foo`},
+		{"apostrophe", "'foo", `\&'foo`},
+		{"escapes", `a-b\c`, `a\-b\ec`},
+		{"newline", "foo\nbar", "foo bar"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := roffLine(c.line); got != c.expect {
+				t.Errorf("got %q but expected %q", got, c.expect)
+			}
+		})
+	}
+}
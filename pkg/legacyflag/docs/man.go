@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+// ManHeader contains the fields of the .TH title line of a man page.
+type ManHeader struct {
+	// Title is the name of the command, e.g. "KUBELET".
+	Title string
+	// Section is the manual section. Default: 1.
+	Section string
+	// Date is the date of the last nontrivial change, e.g. "Jan 2019".
+	Date string
+	// Source is the source of the command, e.g. "Kubernetes".
+	Source string
+	// Manual is the title of the manual, e.g. "Kubernetes User Manuals".
+	Manual string
+}
+
+// Man writes the documentation for all flags in fs as an OPTIONS section of a
+// man page, formatted with roff man macros.
+func Man(w io.Writer, fs *legacyflag.FlagSet, h ManHeader) error {
+	if h.Section == "" {
+		h.Section = "1"
+	}
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, ".TH %s %s %s %s %s\n", roffQuote(h.Title), roffQuote(h.Section),
+		roffQuote(h.Date), roffQuote(h.Source), roffQuote(h.Manual))
+	b.WriteString(".SH OPTIONS\n")
+	for _, f := range Flags(fs) {
+		b.WriteString(".TP\n")
+		if f.Shorthand != "" {
+			fmt.Fprintf(b, `\fB\-%s\fP, `, roffEscape(f.Shorthand))
+		}
+		fmt.Fprintf(b, `\fB\-\-%s\fP=\fI%s\fP`, roffEscape(f.Name), roffEscape(f.Type))
+		if f.Default != "" {
+			fmt.Fprintf(b, ` (default: %s)`, roffEscape(f.Default))
+		}
+		b.WriteString("\n")
+		for _, line := range f.description() {
+			b.WriteString(roffLine(line))
+			b.WriteString("\n.br\n")
+		}
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// roffEscape escapes characters with special meaning in roff text.
+func roffEscape(s string) string {
+	s = strings.Replace(s, `\`, `\e`, -1)
+	return strings.Replace(s, "-", `\-`, -1)
+}
+
+// roffQuote escapes s as a quoted macro argument.
+func roffQuote(s string) string {
+	return `"` + strings.Replace(roffEscape(s), `"`, `\(dq`, -1) + `"`
+}
+
+// roffLine escapes s as a line of roff text. Lines beginning with a control
+// character are protected with a zero-width escape, and embedded newlines are
+// folded into spaces.
+func roffLine(s string) string {
+	s = roffEscape(strings.Replace(s, "\n", " ", -1))
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+// Markdown writes the documentation for all flags in fs as a Markdown table.
+func Markdown(w io.Writer, fs *legacyflag.FlagSet) error {
+	b := &bytes.Buffer{}
+	b.WriteString("| Flag | Type | Default | Description |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, f := range Flags(fs) {
+		name := "`--" + f.Name + "`"
+		if f.Shorthand != "" {
+			name = "`-" + f.Shorthand + "`, " + name
+		}
+		def := ""
+		if f.Default != "" {
+			def = "`" + f.Default + "`"
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s |\n",
+			name, f.Type, markdownEscape(def), markdownEscape(strings.Join(f.description(), " ")))
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// markdownEscape escapes characters that would break a table cell.
+func markdownEscape(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	return strings.Replace(s, "\n", "<br>", -1)
+}
+
+// description returns the usage text for f, followed by notes about its
+// deprecation, visibility, config file equivalent and constraints.
+func (f *Flag) description() []string {
+	d := []string{}
+	if f.Usage != "" {
+		d = append(d, f.Usage)
+	}
+	if f.Deprecated != "" {
+		d = append(d, "DEPRECATED: "+f.Deprecated)
+	}
+	if f.Visibility == legacyflag.VisibilityExperimental {
+		d = append(d, "(experimental)")
+	}
+	if f.Hidden {
+		d = append(d, "(hidden)")
+	}
+	if f.ConfigField != "" {
+		d = append(d, fmt.Sprintf("Config file field: %s.", f.ConfigField))
+	}
+	if len(f.Enum) > 0 {
+		d = append(d, fmt.Sprintf("Allowed values: %s.", strings.Join(f.Enum, ", ")))
+	}
+	if f.Min != nil && f.Max != nil {
+		d = append(d, fmt.Sprintf("Range: [%v, %v].", *f.Min, *f.Max))
+	}
+	return d
+}
@@ -17,24 +17,150 @@ limitations under the License.
 package legacyflag
 
 import (
+	"flag"
+	"os"
+	"path/filepath"
+	"sync"
+
 	"github.com/spf13/pflag"
 )
 
 // FlagSet tracks the registered flags.
 type FlagSet struct {
 	fs *pflag.FlagSet
+	// section is the usage section that flags registered through this
+	// FlagSet are assigned to. Empty for the default section.
+	section string
+	// visibility is the visibility level of flags registered through this
+	// FlagSet.
+	visibility Visibility
+	// state is shared between a FlagSet and its sections.
+	*state
+}
+
+// state is the legacyflag-specific state of a FlagSet.
+type state struct {
+	// name is the program name, if known.
+	name string
+	// meta holds legacyflag-specific metadata, keyed by flag name.
+	meta map[string]*Metadata
+	// values holds the legacyflag value references returned by the
+	// registration methods, keyed by flag name.
+	values map[string]interface{}
+	// exclusive lists groups of mutually exclusive flags.
+	exclusive [][]string
+	// sections lists the usage section titles in declared order.
+	sections []string
+	// expandArgsFiles enables expansion of @argsfile arguments in Parse.
+	expandArgsFiles bool
+	// goFlagSet is the Go flag.FlagSet frontend, if any. See NewFromGoFlagSet.
+	goFlagSet *flag.FlagSet
+	// globals records the global flags that were imported or explicitly
+	// excluded. See AuditGlobals.
+	globals map[GlobalFlag]bool
+	// normalizer implements the normalization policy, if any. See
+	// SetNormalizePolicy.
+	normalizer *normalizer
+	// spellings maps non-canonical flag name spellings seen while parsing to
+	// their canonical names. It is only non-nil during Parse.
+	spellings map[string]string
+	// aliases lists the flag aliases. See Alias.
+	aliases []*alias
+	// aliasPolicy determines how flags set using several names are handled.
+	aliasPolicy AliasPolicy
+	// experimentalGate rejects experimental flags, if set. See
+	// GateExperimental.
+	experimentalGate *experimentalGate
+	// defaults holds the values of the flags when they were registered. See
+	// Reset.
+	defaults map[*pflag.Flag]flagState
+	// args is the command line passed to Parse. See EffectiveConfig.
+	args []string
+	// mu guards the flag values while they are parsed, validated, restored
+	// or reset, against concurrent reads, e.g. by a Reloader.
+	mu sync.RWMutex
 }
 
 // NewFlagSet constructs a new FlagSet.
 func NewFlagSet(name string) *FlagSet {
-	return &FlagSet{
-		fs: pflag.NewFlagSet(name, pflag.ContinueOnError),
-	}
+	fs := NewFromPFlagSet(pflag.NewFlagSet(name, pflag.ContinueOnError))
+	fs.name = name
+	return fs
 }
 
 // NewFromPFlagSet creates a new FlagSet given a PFlagSet.
 func NewFromPFlagSet(fs *pflag.FlagSet) *FlagSet {
-	return &FlagSet{fs: fs}
+	return &FlagSet{
+		fs: fs,
+		state: &state{
+			meta:     make(map[string]*Metadata),
+			values:   make(map[string]interface{}),
+			globals:  make(map[GlobalFlag]bool),
+			defaults: make(map[*pflag.Flag]flagState),
+		},
+	}
+}
+
+// programName returns the name the FlagSet was constructed with, or the base
+// name of the running program if the name is unknown.
+func (fs *FlagSet) programName() string {
+	if fs.name != "" {
+		return fs.name
+	}
+	return filepath.Base(os.Args[0])
+}
+
+// Section returns a view of the FlagSet that assigns the flags registered
+// through it to the named usage section. Sections are rendered by Usage in
+// the order they are first declared.
+func (fs *FlagSet) Section(title string) *FlagSet {
+	fs.addSection(title)
+	return &FlagSet{
+		fs:         fs.fs,
+		section:    title,
+		visibility: fs.visibility,
+		state:      fs.state,
+	}
+}
+
+// addSection declares a usage section, if it was not already declared.
+func (s *state) addSection(title string) {
+	for _, t := range s.sections {
+		if t == title {
+			return
+		}
+	}
+	s.sections = append(s.sections, title)
+}
+
+// register records legacyflag metadata for a newly registered flag. v is the
+// value reference returned by the registration method, or nil if there is
+// none, e.g. for imported global flags.
+func (fs *FlagSet) register(name string, v interface{}) {
+	name = fs.canonical(name)
+	if v != nil {
+		fs.values[name] = v
+	}
+	fs.saveDefault(name)
+	if fs.goFlagSet != nil {
+		fs.addGoFlag(name)
+	}
+	if fs.visibility != VisibilityPublic {
+		fs.MarkVisibility(name, fs.visibility)
+	}
+	if fs.section == "" {
+		return
+	}
+	if m, err := fs.metadata(name); err == nil {
+		m.Section = fs.section
+	}
+}
+
+// Value returns the value reference that the registration method returned
+// for the named flag, e.g. a *StringValue, or nil if there is none, e.g. for
+// imported global flags.
+func (fs *FlagSet) Value(name string) interface{} {
+	return fs.values[fs.canonical(name)]
 }
 
 // PflagFlagSet returns the underlying pflag.FlagSet.
@@ -42,12 +168,47 @@ func (fs *FlagSet) PflagFlagSet() *pflag.FlagSet {
 	return fs.fs
 }
 
+// SetExpandArgsFiles enables or disables expansion of @argsfile arguments in
+// Parse. When enabled, an argument of the form @path is replaced by the
+// arguments read from the file at path, one per line. Args files may include
+// other args files.
+func (fs *FlagSet) SetExpandArgsFiles(enabled bool) {
+	fs.expandArgsFiles = enabled
+}
+
 // Parse parses the flags.
 func (fs *FlagSet) Parse(args []string) error {
-	return fs.fs.Parse(args)
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.args = append([]string{}, args...)
+	for _, a := range fs.aliases {
+		a.resolved = false
+	}
+	if fs.expandArgsFiles {
+		expanded, err := expandArgsFiles(args)
+		if err != nil {
+			return err
+		}
+		args = expanded
+	}
+	if fs.goFlagSet != nil {
+		if err := fs.goFlagSet.Parse(args); err != nil {
+			return err
+		}
+	} else if err := fs.parseNormalized(func() error { return fs.fs.Parse(args) }); err != nil {
+		return err
+	}
+	return fs.validate()
 }
 
 // MarkDeprecated marks a flag as deprecated.
 func (fs *FlagSet) MarkDeprecated(name, message string) error {
 	return fs.fs.MarkDeprecated(name, message)
 }
+
+// MarkShorthandDeprecated marks the shorthand of a flag as deprecated. The
+// flag can still be set using its shorthand, but using it prints the message,
+// and the shorthand is not shown in usage.
+func (fs *FlagSet) MarkShorthandDeprecated(name, message string) error {
+	return fs.fs.MarkShorthandDeprecated(name, message)
+}
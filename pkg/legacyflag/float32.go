@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,36 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
+import (
 	"github.com/spf13/pflag"
-	
 )
 
 // Float32Value is a reference to a registered float32 flag value.
 type Float32Value struct {
-	name string
+	name  string
 	value float32
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // Float32Var registers a flag for float32 against the FlagSet, and returns
 // a Float32Value reference to the registered flag value.
 func (fs *FlagSet) Float32Var(name string, def float32, usage string) *Float32Value {
+	return fs.Float32VarP(name, "", def, usage)
+}
+
+// Float32VarP is like Float32Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) Float32VarP(name, shorthand string, def float32, usage string) *Float32Value {
 	v := &Float32Value{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.Float32Var(&v.value, name, def, usage)
+	fs.fs.Float32VarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -54,3 +60,8 @@ func (v *Float32Value) Apply(apply func(value float32)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *Float32Value) goType() string {
+	return "float32"
+}
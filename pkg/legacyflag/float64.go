@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,36 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
+import (
 	"github.com/spf13/pflag"
-	
 )
 
 // Float64Value is a reference to a registered float64 flag value.
 type Float64Value struct {
-	name string
+	name  string
 	value float64
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // Float64Var registers a flag for float64 against the FlagSet, and returns
 // a Float64Value reference to the registered flag value.
 func (fs *FlagSet) Float64Var(name string, def float64, usage string) *Float64Value {
+	return fs.Float64VarP(name, "", def, usage)
+}
+
+// Float64VarP is like Float64Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) Float64VarP(name, shorthand string, def float64, usage string) *Float64Value {
 	v := &Float64Value{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.Float64Var(&v.value, name, def, usage)
+	fs.fs.Float64VarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -54,3 +60,8 @@ func (v *Float64Value) Apply(apply func(value float64)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *Float64Value) goType() string {
+	return "float64"
+}
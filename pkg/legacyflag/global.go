@@ -19,6 +19,7 @@ package legacyflag
 import (
 	"flag"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spf13/pflag"
@@ -30,9 +31,7 @@ import (
 // panics if the flag doesn't exist.
 func (fs *FlagSet) MustAddGlobalFlag(name string) {
 	if f := flag.CommandLine.Lookup(name); f != nil {
-		pflagFlag := pflag.PFlagFromGoFlag(f)
-		pflagFlag.Name = normalize(pflagFlag.Name)
-		fs.fs.AddFlag(pflagFlag)
+		fs.addGlobalFlag("flag", pflag.PFlagFromGoFlag(f), fs.globalName(f.Name))
 	} else {
 		panic(fmt.Sprintf("failed to find flag in global flagset (flag): %s", name))
 	}
@@ -42,8 +41,7 @@ func (fs *FlagSet) MustAddGlobalFlag(name string) {
 // panics if the flag doesn't exist.
 func (fs *FlagSet) MustAddGlobalPflag(name string) {
 	if f := pflag.CommandLine.Lookup(name); f != nil {
-		f.Name = normalize(f.Name)
-		fs.fs.AddFlag(f)
+		fs.addGlobalFlag("pflag", f, fs.globalName(f.Name))
 	} else {
 		panic(fmt.Sprintf("failed to find flag in global flagset (pflag): %s", name))
 	}
@@ -58,14 +56,189 @@ const deprecated = "This flag has been deprecated and will be removed in a futur
 // line, marks it deprecated, and panics if the flag doesn't exist.
 func (fs *FlagSet) MustAddDeprecatedGlobalFlag(name string) {
 	fs.MustAddGlobalFlag(name)
-	fs.fs.Lookup(normalize(name)).Deprecated = deprecated
+	fs.fs.Lookup(fs.globalName(name)).Deprecated = deprecated
 }
 
 // MustAddDeprecatedGlobalPflag adds the flag from the global pflag command
 // line, marks it deprecated, and panics if the flag doesn't exist.
 func (fs *FlagSet) MustAddDeprecatedGlobalPflag(name string) {
 	fs.MustAddGlobalPflag(name)
-	fs.fs.Lookup(normalize(name)).Deprecated = deprecated
+	fs.fs.Lookup(fs.globalName(name)).Deprecated = deprecated
+}
+
+// GlobalFlagOptions selects and adjusts the flags imported by AddGlobalFlags
+// and AddGlobalPflags. All names refer to the flags' names in the global
+// flagset.
+type GlobalFlagOptions struct {
+	// Include lists the flags to import. If empty, all flags are imported.
+	Include []string
+	// Exclude lists flags that are not imported.
+	Exclude []string
+	// Rename maps flags to the name they are imported as. Flags that are not
+	// renamed are imported with their names normalized by the FlagSet's
+	// normalization policy, or with underscores replaced by hyphens if it has
+	// none.
+	Rename map[string]string
+	// Deprecate maps flags to a deprecation message. An empty message uses a
+	// default message.
+	Deprecate map[string]string
+	// Hide lists flags that are imported but hidden from usage.
+	Hide []string
+}
+
+// AddGlobalFlags adds the flags selected by the options from the global Go
+// flag command line. It returns an error, and adds no flags, if any of the
+// named flags don't exist.
+func (fs *FlagSet) AddGlobalFlags(o GlobalFlagOptions) error {
+	globals := []*pflag.Flag{}
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		globals = append(globals, pflag.PFlagFromGoFlag(f))
+	})
+	return fs.addGlobalFlags("flag", globals, o)
+}
+
+// AddGlobalPflags adds the flags selected by the options from the global
+// pflag command line. It returns an error, and adds no flags, if any of the
+// named flags don't exist.
+func (fs *FlagSet) AddGlobalPflags(o GlobalFlagOptions) error {
+	globals := []*pflag.Flag{}
+	pflag.CommandLine.VisitAll(func(f *pflag.Flag) {
+		globals = append(globals, f)
+	})
+	return fs.addGlobalFlags("pflag", globals, o)
+}
+
+// addGlobalFlags adds the global flags selected by the options. kind names
+// the global flagset in errors.
+func (fs *FlagSet) addGlobalFlags(kind string, globals []*pflag.Flag, o GlobalFlagOptions) error {
+	byName := make(map[string]*pflag.Flag, len(globals))
+	for _, f := range globals {
+		byName[f.Name] = f
+	}
+
+	// check every name the options refer to before adding any flags
+	names := append(append(append([]string{}, o.Include...), o.Exclude...), o.Hide...)
+	for name := range o.Rename {
+		names = append(names, name)
+	}
+	for name := range o.Deprecate {
+		names = append(names, name)
+	}
+	missing := []string{}
+	for _, name := range names {
+		if byName[name] == nil && !contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("failed to find flags in global flagset (%s): %s", kind, strings.Join(missing, ", "))
+	}
+
+	selected := []*pflag.Flag{}
+	for _, f := range globals {
+		if (len(o.Include) > 0 && !contains(o.Include, f.Name)) || contains(o.Exclude, f.Name) {
+			continue
+		}
+		selected = append(selected, f)
+	}
+	newNames := make(map[string]string, len(selected))
+	for _, f := range selected {
+		name := fs.globalName(f.Name)
+		if rename, ok := o.Rename[f.Name]; ok {
+			name = fs.canonical(rename)
+		}
+		if fs.fs.Lookup(name) != nil {
+			return fmt.Errorf("failed to add global flag (%s) %s: flag %q already exists", kind, f.Name, name)
+		}
+		for global, other := range newNames {
+			if other == name {
+				return fmt.Errorf("failed to add global flags (%s) %s and %s: both are imported as %q", kind, global, f.Name, name)
+			}
+		}
+		newNames[f.Name] = name
+	}
+
+	for _, name := range o.Exclude {
+		fs.globals[GlobalFlag{FlagSet: kind, Name: name}] = true
+	}
+	for _, f := range selected {
+		globalName := f.Name
+		fs.addGlobalFlag(kind, f, newNames[globalName])
+		added := fs.fs.Lookup(newNames[globalName])
+		if msg, ok := o.Deprecate[globalName]; ok {
+			if msg == "" {
+				msg = deprecated
+			}
+			added.Deprecated = msg
+		}
+		if contains(o.Hide, globalName) {
+			added.Hidden = true
+		}
+	}
+	return nil
+}
+
+// addGlobalFlag adds the global flag f as name. The added flag is a copy
+// that shares the Value of f, so renaming, deprecating or hiding it doesn't
+// affect the global flagset. kind names the global flagset.
+func (fs *FlagSet) addGlobalFlag(kind string, f *pflag.Flag, name string) {
+	fs.globals[GlobalFlag{FlagSet: kind, Name: f.Name}] = true
+	c := *f
+	c.Name = name
+	if f.Annotations != nil {
+		c.Annotations = make(map[string][]string, len(f.Annotations))
+		for k, v := range f.Annotations {
+			c.Annotations[k] = append([]string(nil), v...)
+		}
+	}
+	fs.fs.AddFlag(&c)
+	fs.register(name, nil)
+}
+
+// GlobalFlag identifies a flag in one of the global flagsets.
+type GlobalFlag struct {
+	// FlagSet is "flag" for the global Go flag command line, and "pflag" for
+	// the global pflag command line.
+	FlagSet string
+	// Name is the name of the flag in the global flagset.
+	Name string
+}
+
+// String returns the flag name and global flagset, e.g. "v (flag)".
+func (g GlobalFlag) String() string {
+	return fmt.Sprintf("%s (%s)", g.Name, g.FlagSet)
+}
+
+// AuditGlobals returns the flags registered in the global Go flag and pflag
+// command lines that the FlagSet neither imported with the AddGlobal methods
+// nor excluded with GlobalFlagOptions.Exclude. Libraries often register
+// global flags at init time, and an unexpected global flag usually means a
+// dependency changed the command line surface it expects the component to
+// expose. The result is sorted by flagset, then name.
+func (fs *FlagSet) AuditGlobals() []GlobalFlag {
+	unexpected := []GlobalFlag{}
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		if g := (GlobalFlag{FlagSet: "flag", Name: f.Name}); !fs.globals[g] {
+			unexpected = append(unexpected, g)
+		}
+	})
+	pflag.CommandLine.VisitAll(func(f *pflag.Flag) {
+		if g := (GlobalFlag{FlagSet: "pflag", Name: f.Name}); !fs.globals[g] {
+			unexpected = append(unexpected, g)
+		}
+	})
+	return unexpected
+}
+
+// contains returns true if the list contains s
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
 }
 
 // normalize replaces underscores with hyphens
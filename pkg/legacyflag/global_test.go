@@ -0,0 +1,220 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func init() {
+	flag.String("global_go_a", "", "a")
+	flag.String("global_go_b", "", "b")
+	flag.Bool("global_go_c", false, "c")
+	pflag.String("global-pflag-a", "", "a")
+	pflag.String("global-pflag-b", "", "b")
+	pflag.String("global_pflag_c", "", "c")
+}
+
+func TestAddGlobalFlags(t *testing.T) {
+	cases := []struct {
+		name string
+		o    GlobalFlagOptions
+
+		// expect
+		flags      []string
+		deprecated map[string]string
+		hidden     []string
+		err        string
+	}{
+		{
+			name:  "include",
+			o:     GlobalFlagOptions{Include: []string{"global_go_a", "global_go_b"}},
+			flags: []string{"global-go-a", "global-go-b"},
+		},
+		{
+			name: "include and exclude",
+			o: GlobalFlagOptions{
+				Include: []string{"global_go_a", "global_go_b", "global_go_c"},
+				Exclude: []string{"global_go_b"},
+			},
+			flags: []string{"global-go-a", "global-go-c"},
+		},
+		{
+			name: "rename, deprecate and hide",
+			o: GlobalFlagOptions{
+				Include:   []string{"global_go_a", "global_go_b", "global_go_c"},
+				Rename:    map[string]string{"global_go_a": "renamed"},
+				Deprecate: map[string]string{"global_go_a": "use --other instead", "global_go_b": ""},
+				Hide:      []string{"global_go_c"},
+			},
+			flags:      []string{"global-go-b", "global-go-c", "renamed"},
+			deprecated: map[string]string{"renamed": "use --other instead", "global-go-b": deprecated},
+			hidden:     []string{"global-go-c"},
+		},
+		{
+			name: "missing flags",
+			o: GlobalFlagOptions{
+				Include: []string{"global_go_a", "missing_b"},
+				Hide:    []string{"missing_a"},
+			},
+			err: "failed to find flags in global flagset (flag): missing_a, missing_b",
+		},
+		{
+			name: "renamed flags collide",
+			o: GlobalFlagOptions{
+				Include: []string{"global_go_a", "global_go_b"},
+				Rename:  map[string]string{"global_go_b": "global-go-a"},
+			},
+			err: "failed to add global flags (flag) global_go_a and global_go_b: both are imported as \"global-go-a\"",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := NewFlagSet("")
+			err := fs.AddGlobalFlags(c.o)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("expected error %q but got %v", c.err, err)
+				}
+				if fs.fs.HasFlags() {
+					t.Errorf("expected no flags to be added")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			flags := []string{}
+			deprecated := map[string]string{}
+			hidden := []string{}
+			fs.fs.VisitAll(func(f *pflag.Flag) {
+				flags = append(flags, f.Name)
+				if f.Deprecated != "" {
+					deprecated[f.Name] = f.Deprecated
+				}
+				if f.Hidden {
+					hidden = append(hidden, f.Name)
+				}
+			})
+			if !reflect.DeepEqual(flags, c.flags) {
+				t.Errorf("flags: got %q but expected %q", flags, c.flags)
+			}
+			if c.deprecated == nil {
+				c.deprecated = map[string]string{}
+			}
+			if !reflect.DeepEqual(deprecated, c.deprecated) {
+				t.Errorf("deprecated: got %q but expected %q", deprecated, c.deprecated)
+			}
+			if c.hidden == nil {
+				c.hidden = []string{}
+			}
+			if !reflect.DeepEqual(hidden, c.hidden) {
+				t.Errorf("hidden: got %q but expected %q", hidden, c.hidden)
+			}
+		})
+	}
+}
+
+func TestAddGlobalPflags(t *testing.T) {
+	fs := NewFlagSet("")
+	if err := fs.AddGlobalPflags(GlobalFlagOptions{Exclude: []string{"global-pflag-b"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fs.fs.Lookup("global-pflag-a") == nil {
+		t.Errorf("expected global-pflag-a to be added")
+	}
+	if fs.fs.Lookup("global-pflag-b") != nil {
+		t.Errorf("expected global-pflag-b to be excluded")
+	}
+
+	err := fs.AddGlobalPflags(GlobalFlagOptions{Include: []string{"global-pflag-a"}})
+	if expect := "failed to add global flag (pflag) global-pflag-a: flag \"global-pflag-a\" already exists"; err == nil || err.Error() != expect {
+		t.Errorf("expected error %q but got %v", expect, err)
+	}
+}
+
+func TestAddGlobalPflagsDoesNotMutateGlobals(t *testing.T) {
+	fs1 := NewFlagSet("")
+	if err := fs1.AddGlobalPflags(GlobalFlagOptions{
+		Include:   []string{"global-pflag-a", "global_pflag_c"},
+		Rename:    map[string]string{"global-pflag-a": "renamed"},
+		Deprecate: map[string]string{"global-pflag-a": ""},
+		Hide:      []string{"global_pflag_c"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fs2 := NewFlagSet("")
+	fs2.MustAddGlobalPflag("global-pflag-a")
+	fs2.MustAddDeprecatedGlobalPflag("global_pflag_c")
+
+	for _, name := range []string{"global-pflag-a", "global_pflag_c"} {
+		f := pflag.CommandLine.Lookup(name)
+		if f == nil {
+			t.Fatalf("pflag.CommandLine.Lookup(%q) failed after import", name)
+		}
+		if f.Name != name || f.Deprecated != "" || f.Hidden {
+			t.Errorf("global flag %q was mutated: name %q, deprecated %q, hidden %t", name, f.Name, f.Deprecated, f.Hidden)
+		}
+	}
+
+	// the imported flags share the global value
+	if err := fs1.Parse([]string{"--renamed=foo"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := pflag.CommandLine.Lookup("global-pflag-a").Value.String(); v != "foo" {
+		t.Errorf("expected the global value to be set to %q but got %q", "foo", v)
+	}
+	if v := fs2.fs.Lookup("global-pflag-a").Value.String(); v != "foo" {
+		t.Errorf("expected the value imported into the second FlagSet to be %q but got %q", "foo", v)
+	}
+	if fs2.fs.Changed("global-pflag-a") {
+		t.Errorf("expected the flag to be unchanged in the second FlagSet")
+	}
+}
+
+func TestAuditGlobals(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.MustAddGlobalFlag("global_go_a")
+	if err := fs.AddGlobalPflags(GlobalFlagOptions{
+		Include: []string{"global-pflag-a"},
+		Exclude: []string{"global-pflag-b"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	unexpected := map[GlobalFlag]bool{}
+	for _, g := range fs.AuditGlobals() {
+		unexpected[g] = true
+	}
+	for g, expect := range map[GlobalFlag]bool{
+		{FlagSet: "flag", Name: "global_go_a"}:     false,
+		{FlagSet: "flag", Name: "global_go_b"}:     true,
+		{FlagSet: "pflag", Name: "global-pflag-a"}: false,
+		{FlagSet: "pflag", Name: "global-pflag-b"}: false,
+		{FlagSet: "pflag", Name: "global_pflag_c"}: true,
+	} {
+		if unexpected[g] != expect {
+			t.Errorf("%s: got unexpected %t but expected %t", g, unexpected[g], expect)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// NewFromGoFlagSet creates a new FlagSet that registers its flags against
+// the given Go flag.FlagSet, for components that parse their command line
+// with the standard library flag package. Parse parses the command line with
+// the Go flag.FlagSet. legacyflag tracks which flags were set itself, so the
+// Set, Merge and Apply methods of the registered flag values behave as they
+// do with a pflag.FlagSet.
+func NewFromGoFlagSet(gofs *flag.FlagSet) *FlagSet {
+	fs := NewFlagSet(gofs.Name())
+	fs.goFlagSet = gofs
+	return fs
+}
+
+// addGoFlag registers the named flag against the Go flag.FlagSet frontend.
+func (fs *FlagSet) addGoFlag(name string) {
+	f := fs.fs.Lookup(name)
+	fs.goFlagSet.Var(&goFlagValue{fs: fs, out: fs.goFlagSet, flag: f}, f.Name, f.Usage)
+}
+
+// goFlagValue implements flag.Value for a flag registered against a Go
+// flag.FlagSet. It sets the underlying legacyflag value and marks the flag as
+// set through pflag.FlagSet.Set, so that e.g. Visit and NFlag of the
+// underlying pflag.FlagSet report it.
+type goFlagValue struct {
+	fs   *FlagSet
+	out  *flag.FlagSet
+	flag *pflag.Flag
+}
+
+// String implements flag.Value
+func (v *goFlagValue) String() string {
+	// the flag package calls String on zero values to detect default values
+	if v == nil || v.flag == nil {
+		return ""
+	}
+	return v.flag.Value.String()
+}
+
+// Set implements flag.Value
+func (v *goFlagValue) Set(value string) error {
+	// the flag package adds the flag name and value to errors
+	if err := v.flag.Value.Set(value); err != nil {
+		return err
+	}
+	if err := v.fs.markSet(v.flag.Name); err != nil {
+		return err
+	}
+	if v.flag.Deprecated != "" {
+		fmt.Fprintf(v.out.Output(), "Flag -%s has been deprecated, %s\n", v.flag.Name, v.flag.Deprecated)
+	}
+	return nil
+}
+
+// IsBoolFlag allows flags that may be set without a value, e.g. bool flags,
+// to be set as -flag.
+func (v *goFlagValue) IsBoolFlag() bool {
+	return v.flag.NoOptDefVal == "true"
+}
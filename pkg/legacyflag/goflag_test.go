@@ -0,0 +1,178 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGoFlagSet(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+
+		// expect
+		str    string
+		strSet bool
+		b      bool
+		bSet   bool
+		m      map[string]string
+		mSet   bool
+		nflag  int
+		rest   []string
+		err    string
+	}{
+		{
+			name: "flags are not set",
+			args: []string{"arg"},
+			str:  "default",
+			m:    map[string]string{"a": "default"},
+			rest: []string{"arg"},
+		},
+		{
+			name:   "flags are set",
+			args:   []string{"-str=foo", "-bool", "-map=b=2", "--map", "c=3", "arg"},
+			str:    "foo",
+			strSet: true,
+			b:      true,
+			bSet:   true,
+			m:      map[string]string{"a": "default", "b": "2", "c": "3"},
+			mSet:   true,
+			nflag:  3,
+			rest:   []string{"arg"},
+		},
+		{
+			name:  "bool flag with explicit value",
+			args:  []string{"-bool=false"},
+			str:   "default",
+			bSet:  true,
+			m:     map[string]string{"a": "default"},
+			nflag: 1,
+			rest:  []string{},
+		},
+		{
+			name: "invalid value",
+			args: []string{"-bool=maybe"},
+			err:  "invalid boolean value \"maybe\" for -bool",
+		},
+		{
+			name: "unknown flag",
+			args: []string{"-unknown"},
+			err:  "flag provided but not defined: -unknown",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gofs := flag.NewFlagSet("test", flag.ContinueOnError)
+			gofs.SetOutput(ioutil.Discard)
+			fs := NewFromGoFlagSet(gofs)
+			strVal := fs.StringVar("str", "", "")
+			bVal := fs.BoolVar("bool", false, "")
+			mVal := fs.MapStringStringVar("map", nil, "", &MapOptions{})
+
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			str := "default"
+			strVal.Set(&str)
+			if str != c.str {
+				t.Errorf("Set: got %q but expected %q", str, c.str)
+			}
+			strSet := false
+			strVal.Apply(func(string) { strSet = true })
+			if strSet != c.strSet {
+				t.Errorf("Apply: got %t but expected %t for -str", strSet, c.strSet)
+			}
+
+			b := false
+			bVal.Set(&b)
+			if b != c.b {
+				t.Errorf("Set: got %t but expected %t", b, c.b)
+			}
+			bSet := false
+			bVal.Apply(func(bool) { bSet = true })
+			if bSet != c.bSet {
+				t.Errorf("Apply: got %t but expected %t for -bool", bSet, c.bSet)
+			}
+
+			m := map[string]string{"a": "default"}
+			mVal.Merge(&m)
+			if !reflect.DeepEqual(m, c.m) {
+				t.Errorf("Merge: got %v but expected %v", m, c.m)
+			}
+			mSet := false
+			mVal.Apply(func(map[string]string) { mSet = true })
+			if mSet != c.mSet {
+				t.Errorf("Apply: got %t but expected %t for -map", mSet, c.mSet)
+			}
+
+			// the pflag.FlagSet sees the flags set through the Go frontend
+			if n := fs.fs.NFlag(); n != c.nflag {
+				t.Errorf("NFlag: got %d but expected %d", n, c.nflag)
+			}
+
+			if rest := gofs.Args(); !reflect.DeepEqual(rest, c.rest) {
+				t.Errorf("Args: got %q but expected %q", rest, c.rest)
+			}
+		})
+	}
+}
+
+func TestGoFlagSetDeprecated(t *testing.T) {
+	gofs := flag.NewFlagSet("test", flag.ContinueOnError)
+	out := &bytes.Buffer{}
+	gofs.SetOutput(out)
+	fs := NewFromGoFlagSet(gofs)
+	fs.StringVar("old", "", "")
+	if err := fs.fs.MarkDeprecated("old", "use -new instead"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Parse([]string{"-old=foo"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expect := "Flag -old has been deprecated, use -new instead\n"; out.String() != expect {
+		t.Errorf("got %q but expected %q", out.String(), expect)
+	}
+}
+
+func TestGoFlagSetValidate(t *testing.T) {
+	gofs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs := NewFromGoFlagSet(gofs)
+	fs.StringVar("mode", "", "")
+	fs.BoolVar("debug", false, "")
+	if err := fs.MarkMutuallyExclusive("mode", "debug"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := fs.Parse([]string{"-mode=c", "-debug"})
+	if expect := "flags --mode, --debug are mutually exclusive, but were set together"; err == nil || err.Error() != expect {
+		t.Errorf("expected error %q but got %v", expect, err)
+	}
+}
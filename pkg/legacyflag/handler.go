@@ -0,0 +1,180 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Sources of flag values, see EffectiveFlag.
+const (
+	// SourceDefault is the source of flags that were not set.
+	SourceDefault = "default"
+	// SourceCommandLine is the source of flags that were set on the command
+	// line.
+	SourceCommandLine = "commandLine"
+)
+
+// EffectiveConfig is the JSON document served by Handler.
+type EffectiveConfig struct {
+	// CommandLine is the command line passed to Parse, with the values of
+	// sensitive flags redacted. Empty if the FlagSet was not parsed with
+	// Parse, e.g. by a command line framework.
+	CommandLine []string `json:"commandLine,omitempty"`
+	// Flags lists the flags, sorted by name.
+	Flags []EffectiveFlag `json:"flags"`
+}
+
+// EffectiveFlag describes the effective value of a flag.
+type EffectiveFlag struct {
+	Name string `json:"name"`
+	// Value and Default are Redacted for sensitive flags.
+	Value   string `json:"value"`
+	Default string `json:"default"`
+	// Source is SourceCommandLine if the flag was set, and SourceDefault
+	// otherwise.
+	Source string `json:"source"`
+	// Alias is the alias the flag was set with, if any, see Alias.
+	Alias               string     `json:"alias,omitempty"`
+	ConfigField         string     `json:"configField,omitempty"`
+	Deprecated          string     `json:"deprecated,omitempty"`
+	ShorthandDeprecated string     `json:"shorthandDeprecated,omitempty"`
+	Visibility          Visibility `json:"visibility,omitempty"`
+	Sensitive           bool       `json:"sensitive,omitempty"`
+}
+
+// EffectiveConfig returns the effective values of the flags, and the command
+// line they were parsed from. The values of sensitive flags, and of their
+// aliases, are Redacted. The FlagSet is read locked, so the result is
+// consistent even if it is concurrently parsed, e.g. by a Reloader's caller.
+func (fs *FlagSet) EffectiveConfig() *EffectiveConfig {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	c := &EffectiveConfig{
+		CommandLine: fs.redactArgs(fs.args),
+		Flags:       []EffectiveFlag{},
+	}
+	fs.fs.VisitAll(func(f *pflag.Flag) {
+		m := fs.Metadata(f.Name)
+		e := EffectiveFlag{
+			Name:                f.Name,
+			Value:               f.Value.String(),
+			Default:             f.DefValue,
+			Source:              SourceDefault,
+			ConfigField:         m.ConfigField,
+			Deprecated:          f.Deprecated,
+			ShorthandDeprecated: f.ShorthandDeprecated,
+			Visibility:          m.Visibility,
+			Sensitive:           fs.sensitive(f.Name),
+		}
+		if f.Changed {
+			e.Source = SourceCommandLine
+		}
+		for _, a := range fs.aliases {
+			if a.new == f.Name && fs.fs.Changed(a.old) {
+				e.Alias = a.old
+			}
+		}
+		if e.Sensitive {
+			e.Value, e.Default = Redacted, Redacted
+		}
+		c.Flags = append(c.Flags, e)
+	})
+	return c
+}
+
+// Handler returns an http.Handler that serves the EffectiveConfig as JSON,
+// like the /configz endpoint of Kubernetes components. It only serves GET
+// and HEAD requests.
+func (fs *FlagSet) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		b, err := json.MarshalIndent(fs.EffectiveConfig(), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(append(b, '\n'))
+	})
+}
+
+// sensitive returns whether the named flag, or the flag it is an alias of, is
+// sensitive.
+func (fs *FlagSet) sensitive(name string) bool {
+	m := fs.flagMetadata(name)
+	return m != nil && m.Sensitive
+}
+
+// redactArgs returns a copy of the command line args with the values of
+// sensitive flags redacted.
+func (fs *FlagSet) redactArgs(args []string) []string {
+	if args == nil {
+		return nil
+	}
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		var f *pflag.Flag
+		// prefix is the part of the arg before the value
+		prefix := ""
+		if strings.HasPrefix(arg, "--") {
+			name := strings.SplitN(arg[2:], "=", 2)[0]
+			f = fs.fs.Lookup(name)
+			prefix = "--" + name
+		} else {
+			// a group of shorthands, the last of which may take a value
+			for j := 1; j < len(arg) && arg[j] != '='; j++ {
+				f = fs.fs.ShorthandLookup(arg[j : j+1])
+				prefix = arg[:j+1]
+				if f == nil || f.NoOptDefVal == "" {
+					break
+				}
+			}
+		}
+		if f == nil || !fs.sensitive(f.Name) {
+			continue
+		}
+		switch {
+		case len(arg) > len(prefix) && arg[len(prefix)] == '=':
+			redacted[i] = prefix + "=" + Redacted
+		case len(arg) > len(prefix):
+			// a shorthand followed directly by its value, e.g. -tvalue
+			redacted[i] = prefix + Redacted
+		case f.NoOptDefVal == "" && i+1 < len(redacted):
+			// the value is the next arg
+			i++
+			redacted[i] = Redacted
+		}
+	}
+	return redacted
+}
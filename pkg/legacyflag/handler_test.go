@@ -0,0 +1,189 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.PflagFlagSet().SetOutput(ioutil.Discard)
+	fs.StringVar("name", "default", "")
+	fs.StringVarP("token", "t", "", "")
+	fs.BoolVarP("verbose", "v", false, "")
+	fs.WithVisibility(VisibilityExperimental).BoolVar("old", false, "")
+	for _, err := range []error{
+		fs.MarkConfigField("name", "Name"),
+		fs.MarkSensitive("token"),
+		fs.Alias("old-name", "name", "use --name"),
+		fs.MarkDeprecated("old", "gone"),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := fs.Parse([]string{"--old-name=a", "-vt", "s1", "--token=s2", "arg", "--", "--token=arg"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	fs.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/configz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	got := EffectiveConfig{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect := EffectiveConfig{
+		CommandLine: []string{"--old-name=a", "-vt", Redacted, "--token=" + Redacted, "arg", "--", "--token=arg"},
+		Flags: []EffectiveFlag{
+			{Name: "name", Value: "a", Default: "default", Source: SourceCommandLine, Alias: "old-name", ConfigField: "Name"},
+			{Name: "old", Value: "false", Default: "false", Source: SourceDefault, Deprecated: "gone", Visibility: VisibilityExperimental},
+			{Name: "old-name", Value: "a", Default: "default", Source: SourceCommandLine, Deprecated: "use --name"},
+			{Name: "token", Value: Redacted, Default: Redacted, Source: SourceCommandLine, Sensitive: true},
+			{Name: "verbose", Value: "true", Default: "false", Source: SourceCommandLine},
+		},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("got:\n%s\nexpected:\n%#v", rec.Body, expect)
+	}
+}
+
+func TestEffectiveConfigSensitiveAlias(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.PflagFlagSet().SetOutput(ioutil.Discard)
+	fs.StringVar("token", "", "")
+	for _, err := range []error{
+		fs.MarkSensitive("token"),
+		fs.Alias("old-token", "token", "use --token"),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := fs.Parse([]string{"--old-token=hunter2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := fs.EffectiveConfig()
+	if expect := []string{"--old-token=" + Redacted}; !reflect.DeepEqual(c.CommandLine, expect) {
+		t.Errorf("got command line %q but expected %q", c.CommandLine, expect)
+	}
+	for _, f := range c.Flags {
+		if (f.Name == "token" || f.Name == "old-token") && (f.Value != Redacted || !f.Sensitive) {
+			t.Errorf("flag --%s is not redacted: %#v", f.Name, f)
+		}
+	}
+}
+
+func TestHandlerNotParsed(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.StringVar("name", "default", "")
+	fs.BoolVar("verbose", false, "")
+	rec := httptest.NewRecorder()
+	fs.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/configz", nil))
+	got := EffectiveConfig{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.CommandLine != nil {
+		t.Errorf("unexpected command line %q", got.CommandLine)
+	}
+	for _, f := range got.Flags {
+		if f.Source != SourceDefault {
+			t.Errorf("flag --%s: unexpected source %q", f.Name, f.Source)
+		}
+	}
+}
+
+func TestHandlerMethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewFlagSet("").Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/configz", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "GET, HEAD" {
+		t.Errorf("unexpected Allow header %q", allow)
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	cases := []struct {
+		name   string
+		args   []string
+		expect []string
+	}{
+		{
+			name:   "no sensitive flags",
+			args:   []string{"--name=a", "-v", "arg"},
+			expect: []string{"--name=a", "-v", "arg"},
+		},
+		{
+			name:   "value in the next arg",
+			args:   []string{"--token", "a", "-t", "b"},
+			expect: []string{"--token", Redacted, "-t", Redacted},
+		},
+		{
+			name:   "value after equals sign",
+			args:   []string{"--token=a", "-t=b", "--token="},
+			expect: []string{"--token=" + Redacted, "-t=" + Redacted, "--token=" + Redacted},
+		},
+		{
+			name:   "shorthand followed by value",
+			args:   []string{"-ta", "-vtb"},
+			expect: []string{"-t" + Redacted, "-vt" + Redacted},
+		},
+		{
+			name:   "missing value",
+			args:   []string{"--token"},
+			expect: []string{"--token"},
+		},
+		{
+			name:   "args after terminator",
+			args:   []string{"--", "--token=a"},
+			expect: []string{"--", "--token=a"},
+		},
+	}
+	fs := NewFlagSet("")
+	fs.StringVar("name", "", "")
+	fs.StringVarP("token", "t", "", "")
+	fs.BoolVarP("verbose", "v", false, "")
+	if err := fs.MarkSensitive("token"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			args := append([]string{}, c.args...)
+			got := fs.redactArgs(args)
+			if !reflect.DeepEqual(got, c.expect) {
+				t.Errorf("got %q but expected %q", got, c.expect)
+			}
+			if !reflect.DeepEqual(args, c.args) {
+				t.Errorf("redactArgs modified its argument: %q", args)
+			}
+		})
+	}
+}
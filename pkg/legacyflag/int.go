@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,36 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
+import (
 	"github.com/spf13/pflag"
-	
 )
 
 // IntValue is a reference to a registered int flag value.
 type IntValue struct {
-	name string
+	name  string
 	value int
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // IntVar registers a flag for int against the FlagSet, and returns
 // a IntValue reference to the registered flag value.
 func (fs *FlagSet) IntVar(name string, def int, usage string) *IntValue {
+	return fs.IntVarP(name, "", def, usage)
+}
+
+// IntVarP is like IntVar, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) IntVarP(name, shorthand string, def int, usage string) *IntValue {
 	v := &IntValue{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.IntVar(&v.value, name, def, usage)
+	fs.fs.IntVarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -54,3 +60,8 @@ func (v *IntValue) Apply(apply func(value int)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *IntValue) goType() string {
+	return "int"
+}
@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,33 +14,63 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
-	"testing"
+import (
+	"io/ioutil"
 	"reflect"
-	
+	"strings"
+	"testing"
 )
 
 func TestInt16Var(t *testing.T) {
 	cases := []struct {
-		name string
-		args []string
+		name  string
+		args  []string
+		def   int16
 		set   int16
 		apply bool
+		err   string
 	}{
 		{
-			name: "flag is set",
-			args: []string{"--foo=-1"},
-			set: -1,
+			name:  "flag is set",
+			args:  []string{"--foo=-1"},
+			set:   -1,
+			apply: true,
+		},
+		{
+			name:  "flag is set with shorthand",
+			args:  []string{"-f=-1"},
+			set:   -1,
+			apply: true,
+		},
+		{
+			name:  "flag is set with alias",
+			args:  []string{"--old-foo=-1"},
+			set:   -1,
 			apply: true,
 		},
 		{
 			name: "flag is not set",
-			args: []string{""},
-			apply: false,
+			args: []string{},
+		},
+		{
+			name: "default is not copied when flag is not set",
+			args: []string{},
+			def:  5,
+		},
+		{
+			name:  "minimum",
+			args:  []string{"--foo=-32768"},
+			set:   -32768,
+			apply: true,
+		},
+		{
+			name: "out of range",
+			args: []string{"--foo=32768"},
+			err:  "invalid argument \"32768\" for \"-f, --foo\" flag",
 		},
 	}
 
@@ -49,8 +79,19 @@ func TestInt16Var(t *testing.T) {
 			var target int16
 
 			fs := NewFlagSet("")
-			val := fs.Int16Var("foo", target, "")
-			if err := fs.Parse(c.args); err != nil {
+			val := fs.Int16VarP("foo", "f", c.def, "")
+			fs.PflagFlagSet().SetOutput(ioutil.Discard)
+			if err := fs.Alias("old-foo", "foo", ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,36 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
+import (
 	"github.com/spf13/pflag"
-	
 )
 
 // Int8Value is a reference to a registered int8 flag value.
 type Int8Value struct {
-	name string
+	name  string
 	value int8
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // Int8Var registers a flag for int8 against the FlagSet, and returns
 // a Int8Value reference to the registered flag value.
 func (fs *FlagSet) Int8Var(name string, def int8, usage string) *Int8Value {
+	return fs.Int8VarP(name, "", def, usage)
+}
+
+// Int8VarP is like Int8Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) Int8VarP(name, shorthand string, def int8, usage string) *Int8Value {
 	v := &Int8Value{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.Int8Var(&v.value, name, def, usage)
+	fs.fs.Int8VarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -54,3 +60,8 @@ func (v *Int8Value) Apply(apply func(value int8)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *Int8Value) goType() string {
+	return "int8"
+}
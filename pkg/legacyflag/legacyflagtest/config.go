@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflagtest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+// ApplyConfig copies the values of the flags of fs that were set to the
+// fields of cfg, a pointer to a ComponentConfig struct, named by the flags'
+// ConfigField metadata. Nested fields are separated by dots, and nil struct
+// pointers along the path are allocated. Flags without a ConfigField are
+// ignored.
+//
+// Flags are applied with the Set method of their value reference, so
+// ApplyConfig is equivalent to calling Set for every flag with a ConfigField.
+// Flags without a value reference, e.g. flags registered with Var or imported
+// global flags, are applied by copying the pflag.Value if its underlying type
+// matches the field type. Components typically apply their flags explicitly;
+// ApplyConfig lets tests apply them without repeating that wiring.
+func ApplyConfig(fs *legacyflag.FlagSet, cfg interface{}) error {
+	root, err := configRoot(cfg)
+	if err != nil {
+		return err
+	}
+	for _, f := range configFlags(fs) {
+		if !f.Changed {
+			continue
+		}
+		field, err := configFieldValue(root, fs.Metadata(f.Name).ConfigField)
+		if err != nil {
+			return fmt.Errorf("flag --%s: %v", f.Name, err)
+		}
+		set, err := configSetter(fs, f, field)
+		if err != nil {
+			return err
+		}
+		if err := set(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateConfigFields checks that the ConfigField of every flag of fs names
+// a field of cfg, a pointer to a ComponentConfig struct, that ApplyConfig can
+// copy the flag value to. cfg is not modified.
+func ValidateConfigFields(fs *legacyflag.FlagSet, cfg interface{}) error {
+	root, err := configRoot(cfg)
+	if err != nil {
+		return err
+	}
+	// resolve the fields in a scratch copy, since resolving allocates pointers
+	scratch := reflect.New(root.Type()).Elem()
+	for _, f := range configFlags(fs) {
+		field, err := configFieldValue(scratch, fs.Metadata(f.Name).ConfigField)
+		if err != nil {
+			return fmt.Errorf("flag --%s: %v", f.Name, err)
+		}
+		if _, err := configSetter(fs, f, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configFlags returns the flags of fs with a ConfigField, sorted by name.
+func configFlags(fs *legacyflag.FlagSet) []*pflag.Flag {
+	flags := []*pflag.Flag{}
+	fs.PflagFlagSet().VisitAll(func(f *pflag.Flag) {
+		if fs.Metadata(f.Name).ConfigField != "" {
+			flags = append(flags, f)
+		}
+	})
+	return flags
+}
+
+// configSetter returns a func that copies the value of the flag f to field,
+// or an error if the flag value can't be copied to the field.
+func configSetter(fs *legacyflag.FlagSet, f *pflag.Flag, field reflect.Value) (func() error, error) {
+	switch ref := fs.Value(f.Name).(type) {
+	case *legacyflag.VarValue, nil:
+		// copy the pflag.Value below
+	default:
+		// the generated value references and TypedVarValue have a
+		// Set(target *T) method
+		set := reflect.ValueOf(ref).MethodByName("Set")
+		if set.IsValid() && set.Type().NumIn() == 1 && set.Type().In(0) == field.Addr().Type() {
+			return func() error {
+				set.Call([]reflect.Value{field.Addr()})
+				return nil
+			}, nil
+		}
+	}
+	value := reflect.ValueOf(f.Value)
+	if value.Kind() == reflect.Ptr {
+		if elem := value.Elem(); elem.Kind() == field.Kind() && elem.Type().ConvertibleTo(field.Type()) {
+			return func() error {
+				field.Set(elem.Convert(field.Type()))
+				return nil
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("flag --%s: can't set config field %s of type %s", f.Name, fs.Metadata(f.Name).ConfigField, field.Type())
+}
+
+// configRoot returns the struct cfg points to.
+func configRoot(cfg interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("config must be a non-nil pointer to a struct, got %T", cfg)
+	}
+	return v.Elem(), nil
+}
+
+// configFieldValue returns the field of the struct v named by the dot
+// separated path, allocating nil struct pointers along the path.
+func configFieldValue(v reflect.Value, path string) (reflect.Value, error) {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("config field %s: %s is not a struct", path, v.Type())
+		}
+		f, ok := v.Type().FieldByName(name)
+		if !ok || f.PkgPath != "" {
+			return reflect.Value{}, fmt.Errorf("config field %s: %s has no exported field %s", path, v.Type(), name)
+		}
+		v = v.FieldByIndex(f.Index)
+	}
+	return v, nil
+}
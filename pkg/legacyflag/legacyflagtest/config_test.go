@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflagtest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+// listValue is a pflag.Value for a list, without a Get method.
+type listValue []string
+
+func (l *listValue) String() string     { return strings.Join(*l, ",") }
+func (l *listValue) Set(s string) error { *l = append(*l, strings.Split(s, ",")...); return nil }
+func (l *listValue) Type() string       { return "list" }
+
+// modeValue is a pflag.Value for a string.
+type modeValue string
+
+func (m *modeValue) String() string     { return string(*m) }
+func (m *modeValue) Set(s string) error { *m = modeValue(s); return nil }
+func (m *modeValue) Type() string       { return "mode" }
+
+type authConfig struct {
+	Anonymous struct {
+		Enabled bool
+	}
+}
+
+type applyConfig struct {
+	Name   string
+	Port   int32
+	Labels map[string]string
+	List   listValue
+	Mode   string
+	Auth   *authConfig
+}
+
+func TestApplyConfig(t *testing.T) {
+	cases := []struct {
+		name   string
+		args   []string
+		expect applyConfig
+	}{
+		{
+			name:   "no flags are set",
+			args:   []string{"--no-config"},
+			expect: applyConfig{Name: "file", Port: 1},
+		},
+		{
+			name: "all flags are set",
+			args: []string{"--name=flag", "--port=2", "--labels=a=b", "--list=x,y", "--mode=m", "--anonymous-auth"},
+			expect: applyConfig{
+				Name:   "flag",
+				Port:   2,
+				Labels: map[string]string{"a": "b"},
+				List:   listValue{"x", "y"},
+				Mode:   "m",
+				Auth:   &authConfig{Anonymous: struct{ Enabled bool }{Enabled: true}},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := legacyflag.NewFlagSet("")
+			fs.StringVar("name", "", "")
+			fs.Int32Var("port", 0, "")
+			fs.MapStringStringVar("labels", nil, "", &legacyflag.MapOptions{})
+			legacyflag.TypedVar[listValue](fs, &listValue{}, "list", "", nil)
+			m := modeValue("")
+			fs.Var(&m, "mode", "")
+			fs.BoolVar("anonymous-auth", false, "")
+			fs.BoolVar("no-config", false, "")
+			for name, field := range map[string]string{
+				"name":           "Name",
+				"port":           "Port",
+				"labels":         "Labels",
+				"list":           "List",
+				"mode":           "Mode",
+				"anonymous-auth": "Auth.Anonymous.Enabled",
+			} {
+				if err := fs.MarkConfigField(name, field); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if err := fs.Parse(c.args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			cfg := applyConfig{Name: "file", Port: 1}
+			if err := ApplyConfig(fs, &cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cfg, c.expect) {
+				t.Errorf("got %#v but expected %#v", cfg, c.expect)
+			}
+		})
+	}
+}
+
+func TestValidateConfigFields(t *testing.T) {
+	cases := []struct {
+		name  string
+		field string
+		cfg   interface{}
+		err   string
+	}{
+		{
+			name:  "valid",
+			field: "Auth.Anonymous.Enabled",
+			cfg:   &applyConfig{},
+		},
+		{
+			name:  "missing field",
+			field: "Auth.Anonymous.Disabled",
+			cfg:   &applyConfig{},
+			err:   "flag --anonymous-auth: config field Auth.Anonymous.Disabled: struct { Enabled bool } has no exported field Disabled",
+		},
+		{
+			name:  "field is not a struct",
+			field: "Name.Anonymous",
+			cfg:   &applyConfig{},
+			err:   "flag --anonymous-auth: config field Name.Anonymous: string is not a struct",
+		},
+		{
+			name:  "wrong type",
+			field: "Port",
+			cfg:   &applyConfig{},
+			err:   "flag --anonymous-auth: can't set config field Port of type int32",
+		},
+		{
+			name:  "config is not a struct pointer",
+			field: "Auth.Anonymous.Enabled",
+			cfg:   applyConfig{},
+			err:   "config must be a non-nil pointer to a struct, got legacyflagtest.applyConfig",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := legacyflag.NewFlagSet("")
+			fs.BoolVar("anonymous-auth", false, "")
+			if err := fs.MarkConfigField("anonymous-auth", c.field); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := ValidateConfigFields(fs, c.cfg)
+			if c.err == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+				t.Errorf("expected error %q but got %v", c.err, err)
+			}
+		})
+	}
+}
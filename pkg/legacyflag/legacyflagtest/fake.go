@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflagtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Env is a fake environment, for components that read environment variables
+// through a lookup func, e.g. os.LookupEnv, rather than from the process
+// environment.
+type Env map[string]string
+
+// LookupEnv is like os.LookupEnv.
+func (e Env) LookupEnv(key string) (string, bool) {
+	v, ok := e[key]
+	return v, ok
+}
+
+// Getenv is like os.Getenv.
+func (e Env) Getenv(key string) string {
+	return e[key]
+}
+
+// Environ is like os.Environ. The result is sorted.
+func (e Env) Environ() []string {
+	env := make([]string, 0, len(e))
+	for k, v := range e {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// ConfigSource is a fake config file source, which decodes an in-memory
+// config file.
+type ConfigSource struct {
+	// Data is the JSON content of the config file.
+	Data []byte
+	// Err is returned by Load instead of decoding Data, if set, e.g. to test
+	// how a component handles a missing config file.
+	Err error
+	// Loads counts the calls to Load.
+	Loads int
+}
+
+// Load decodes Data into cfg, a pointer to a ComponentConfig struct. Like a
+// strict config file loader, it rejects unknown fields.
+func (s *ConfigSource) Load(cfg interface{}) error {
+	s.Loads++
+	if s.Err != nil {
+		return s.Err
+	}
+	d := json.NewDecoder(bytes.NewReader(s.Data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(cfg); err != nil {
+		return fmt.Errorf("failed to decode config: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflagtest
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+// FuzzArgs fuzzes parsing random command lines with the FlagSets returned by
+// newFlagSet, to catch panics in custom pflag.Value parsers. Call it from a
+// Fuzz function of a component's tests. newFlagSet must return a new FlagSet
+// for every call, and shouldn't enable args file expansion.
+//
+// Each fuzz input is split into arguments at newlines. The corpus is seeded
+// with a command line setting each flag to its default value. Parse errors
+// are expected, and ignored.
+func FuzzArgs(f *testing.F, newFlagSet func() *legacyflag.FlagSet) {
+	f.Add("")
+	newFlagSet().PflagFlagSet().VisitAll(func(fl *pflag.Flag) {
+		f.Add("--" + fl.Name + "=" + argValue(fl))
+	})
+	f.Fuzz(func(t *testing.T, argv string) {
+		fs := newFlagSet()
+		fs.PflagFlagSet().SetOutput(ioutil.Discard)
+		_ = fs.Parse(strings.Split(argv, "\n"))
+	})
+}
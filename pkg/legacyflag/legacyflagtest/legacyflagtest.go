@@ -0,0 +1,175 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package legacyflagtest provides helpers for testing components that use
+// legacyflag.
+package legacyflagtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+// AssertNoUnexpectedGlobals fails the test for each global flag that fs
+// neither imported nor explicitly excluded, see legacyflag.AuditGlobals.
+// Call it from a component's tests after building its FlagSet, so that a
+// dependency bump that registers a new global flag fails the build instead of
+// silently going unnoticed. Flags registered by the testing package are
+// ignored.
+func AssertNoUnexpectedGlobals(t testing.TB, fs *legacyflag.FlagSet) {
+	t.Helper()
+	for _, g := range fs.AuditGlobals() {
+		if g.FlagSet == "flag" && strings.HasPrefix(g.Name, "test.") {
+			continue
+		}
+		t.Errorf("unexpected global flag %s: import it with AddGlobalFlags or AddGlobalPflags, or exclude it explicitly", g)
+	}
+}
+
+// ParseAndApply parses args with fs, and applies the flags that were set to
+// cfg, a pointer to a ComponentConfig struct, see ApplyConfig. It fails the
+// test if either step fails.
+func ParseAndApply(t testing.TB, fs *legacyflag.FlagSet, args []string, cfg interface{}) {
+	t.Helper()
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("failed to parse %q: %v", args, err)
+	}
+	if err := ApplyConfig(fs, cfg); err != nil {
+		t.Fatalf("failed to apply flags: %v", err)
+	}
+}
+
+// AssertGoldenConfig compares the effective config cfg, encoded as indented
+// JSON, to the golden file at path. If update is set, it writes the golden
+// file instead. Tests typically pass the value of an -update flag.
+func AssertGoldenConfig(t testing.TB, path string, cfg interface{}, update bool) {
+	t.Helper()
+	got, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to encode config: %v", err)
+	}
+	got = append(got, '\n')
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+	expect, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(got, expect) {
+		t.Errorf("effective config does not match golden file %s\ngot:\n%s\nexpected:\n%s", path, got, expect)
+	}
+}
+
+// AssertConfigFields fails the test for each flag of fs that has no config
+// file equivalent, and if the config fields don't match cfg, see
+// ValidateConfigFields. Deprecated flags are not required to have a config
+// field, and flags that intentionally have none can be exempted by name.
+func AssertConfigFields(t testing.TB, fs *legacyflag.FlagSet, cfg interface{}, exempt ...string) {
+	t.Helper()
+	for _, name := range exempt {
+		if fs.PflagFlagSet().Lookup(name) == nil {
+			t.Errorf("exempt flag --%s does not exist", name)
+		}
+	}
+	fs.PflagFlagSet().VisitAll(func(f *pflag.Flag) {
+		if f.Deprecated != "" || contains(exempt, f.Name) || fs.Metadata(f.Name).ConfigField != "" {
+			return
+		}
+		t.Errorf("flag --%s has no config field: mark it with MarkConfigField, or exempt it", f.Name)
+	})
+	if err := ValidateConfigFields(fs, cfg); err != nil {
+		t.Error(err)
+	}
+}
+
+// AssertDeprecatedFlagsParse fails the test for each deprecated flag, or flag
+// with a deprecated shorthand, that can no longer be set, so that deprecated
+// flags keep working until they are removed. newFlagSet must return a new
+// FlagSet for every call, since each flag is parsed separately. Flags are set
+// to the value in values, or to their default value.
+func AssertDeprecatedFlagsParse(t testing.TB, newFlagSet func() *legacyflag.FlagSet, values map[string]string) {
+	t.Helper()
+	// each command line sets the named flag
+	cases := map[string][][]string{}
+	newFlagSet().PflagFlagSet().VisitAll(func(f *pflag.Flag) {
+		value, ok := values[f.Name]
+		if !ok {
+			value = argValue(f)
+		}
+		if f.Deprecated != "" {
+			cases[f.Name] = append(cases[f.Name], []string{"--" + f.Name + "=" + value})
+		}
+		if f.ShorthandDeprecated != "" {
+			args := []string{"-" + f.Shorthand + "=" + value}
+			if value == "" && f.NoOptDefVal == "" {
+				args = []string{"-" + f.Shorthand, value}
+			}
+			cases[f.Name] = append(cases[f.Name], args)
+		}
+	})
+	names := make([]string, 0, len(cases))
+	for name := range cases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, args := range cases[name] {
+			fs := newFlagSet()
+			fs.PflagFlagSet().SetOutput(ioutil.Discard)
+			if err := fs.Parse(args); err != nil {
+				t.Errorf("deprecated flag %s no longer parses: %v", args[0], err)
+			} else if !fs.PflagFlagSet().Changed(name) {
+				t.Errorf("deprecated flag %s no longer sets --%s", args[0], name)
+			}
+		}
+	}
+}
+
+// argValue returns a command line value for the default value of f.
+func argValue(f *pflag.Flag) string {
+	// slice flags print empty defaults as "[]", which doesn't parse
+	if f.DefValue == "[]" {
+		return ""
+	}
+	return f.DefValue
+}
+
+// contains returns true if the list contains s
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
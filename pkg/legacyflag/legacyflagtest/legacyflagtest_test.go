@@ -0,0 +1,334 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflagtest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+func init() {
+	flag.String("leaked_go", "", "")
+	flag.String("imported_go", "", "")
+	pflag.String("leaked-pflag", "", "")
+}
+
+// fakeT records errors instead of failing the test.
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Error(args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprint(args...))
+}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// run calls f in a new goroutine, so that Fatalf can stop it.
+func (t *fakeT) run(f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	<-done
+}
+
+type config struct {
+	Name    string
+	Labels  map[string]string
+	Verbose bool
+}
+
+// newFlagSet returns a FlagSet for config, with a deprecated alias and a
+// deprecated shorthand.
+func newFlagSet() *legacyflag.FlagSet {
+	fs := legacyflag.NewFlagSet("test")
+	fs.StringVar("name", "", "")
+	fs.MapStringStringVar("labels", nil, "", &legacyflag.MapOptions{})
+	fs.BoolVarP("verbose", "v", false, "")
+	for _, err := range []error{
+		fs.MarkConfigField("name", "Name"),
+		fs.MarkConfigField("labels", "Labels"),
+		fs.MarkConfigField("verbose", "Verbose"),
+		fs.Alias("old-name", "name", ""),
+		fs.MarkShorthandDeprecated("verbose", "use --verbose instead"),
+	} {
+		if err != nil {
+			panic(err)
+		}
+	}
+	return fs
+}
+
+func TestAssertNoUnexpectedGlobals(t *testing.T) {
+	fs := legacyflag.NewFlagSet("")
+	fs.MustAddGlobalFlag("imported_go")
+
+	ft := &fakeT{TB: t}
+	AssertNoUnexpectedGlobals(ft, fs)
+	expect := []string{
+		"unexpected global flag leaked_go (flag): import it with AddGlobalFlags or AddGlobalPflags, or exclude it explicitly",
+		"unexpected global flag leaked-pflag (pflag): import it with AddGlobalFlags or AddGlobalPflags, or exclude it explicitly",
+	}
+	if !reflect.DeepEqual(ft.errors, expect) {
+		t.Errorf("got %q but expected %q", ft.errors, expect)
+	}
+
+	fs = legacyflag.NewFlagSet("")
+	if err := fs.AddGlobalFlags(legacyflag.GlobalFlagOptions{
+		Include: []string{"imported_go"},
+		Exclude: []string{"leaked_go"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fs.MustAddGlobalPflag("leaked-pflag")
+	ft = &fakeT{TB: t}
+	AssertNoUnexpectedGlobals(ft, fs)
+	if len(ft.errors) > 0 {
+		t.Errorf("unexpected errors: %q", ft.errors)
+	}
+}
+
+func TestParseAndApply(t *testing.T) {
+	cfg := &config{Name: "file", Verbose: true}
+	ParseAndApply(t, newFlagSet(), []string{"--old-name=flag", "--labels=a=b"}, cfg)
+	expect := &config{Name: "flag", Labels: map[string]string{"a": "b"}, Verbose: true}
+	if !reflect.DeepEqual(cfg, expect) {
+		t.Errorf("got %#v but expected %#v", cfg, expect)
+	}
+
+	ft := &fakeT{TB: t}
+	ft.run(func() { ParseAndApply(ft, newFlagSet(), []string{"--unknown"}, cfg) })
+	if len(ft.errors) != 1 || !strings.HasPrefix(ft.errors[0], `failed to parse ["--unknown"]: unknown flag: --unknown`) {
+		t.Errorf("unexpected errors: %q", ft.errors)
+	}
+}
+
+func TestAssertGoldenConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "legacyflagtest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "testdata", "config.json")
+	cfg := &config{Name: "a"}
+
+	AssertGoldenConfig(t, path, cfg, true)
+	AssertGoldenConfig(t, path, cfg, false)
+
+	ft := &fakeT{TB: t}
+	ft.run(func() { AssertGoldenConfig(ft, path, &config{Name: "b"}, false) })
+	if len(ft.errors) != 1 || !strings.HasPrefix(ft.errors[0], "effective config does not match golden file "+path) {
+		t.Errorf("unexpected errors: %q", ft.errors)
+	}
+
+	ft = &fakeT{TB: t}
+	ft.run(func() { AssertGoldenConfig(ft, filepath.Join(dir, "missing.json"), cfg, false) })
+	if len(ft.errors) != 1 || !strings.HasPrefix(ft.errors[0], "failed to read golden file") {
+		t.Errorf("unexpected errors: %q", ft.errors)
+	}
+}
+
+func TestAssertConfigFields(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(fs *legacyflag.FlagSet)
+		exempt []string
+		expect []string
+	}{
+		{
+			name:   "all flags have config fields",
+			modify: func(fs *legacyflag.FlagSet) {},
+		},
+		{
+			name:   "flag without config field",
+			modify: func(fs *legacyflag.FlagSet) { fs.StringVar("debug", "", "") },
+			expect: []string{"flag --debug has no config field: mark it with MarkConfigField, or exempt it"},
+		},
+		{
+			name:   "exempt flag without config field",
+			modify: func(fs *legacyflag.FlagSet) { fs.StringVar("debug", "", "") },
+			exempt: []string{"debug"},
+		},
+		{
+			name:   "exempt flag does not exist",
+			modify: func(fs *legacyflag.FlagSet) {},
+			exempt: []string{"debug"},
+			expect: []string{"exempt flag --debug does not exist"},
+		},
+		{
+			name: "config field does not exist",
+			modify: func(fs *legacyflag.FlagSet) {
+				fs.StringVar("debug", "", "")
+				fs.MarkConfigField("debug", "Debug")
+			},
+			expect: []string{"flag --debug: config field Debug: legacyflagtest.config has no exported field Debug"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := newFlagSet()
+			c.modify(fs)
+			ft := &fakeT{TB: t}
+			AssertConfigFields(ft, fs, &config{}, c.exempt...)
+			if !reflect.DeepEqual(ft.errors, c.expect) {
+				t.Errorf("got %q but expected %q", ft.errors, c.expect)
+			}
+		})
+	}
+}
+
+func TestAssertDeprecatedFlagsParse(t *testing.T) {
+	ft := &fakeT{TB: t}
+	AssertDeprecatedFlagsParse(ft, newFlagSet, map[string]string{"old-name": "a"})
+	if len(ft.errors) > 0 {
+		t.Errorf("unexpected errors: %q", ft.errors)
+	}
+
+	// a deprecated flag whose default value doesn't parse
+	broken := func() *legacyflag.FlagSet {
+		fs := newFlagSet()
+		fs.Int32Var("old-port", 0, "")
+		fs.MarkDeprecated("old-port", "use --port instead")
+		return fs
+	}
+	ft = &fakeT{TB: t}
+	AssertDeprecatedFlagsParse(ft, broken, map[string]string{"old-port": "x"})
+	if len(ft.errors) != 1 || !strings.HasPrefix(ft.errors[0], `deprecated flag --old-port=x no longer parses: invalid argument "x"`) {
+		t.Errorf("unexpected errors: %q", ft.errors)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	env := Env{"B": "2", "A": "1"}
+	if v, ok := env.LookupEnv("A"); v != "1" || !ok {
+		t.Errorf("LookupEnv: got %q, %v", v, ok)
+	}
+	if v, ok := env.LookupEnv("C"); v != "" || ok {
+		t.Errorf("LookupEnv: got %q, %v", v, ok)
+	}
+	if v := env.Getenv("B"); v != "2" {
+		t.Errorf("Getenv: got %q", v)
+	}
+	if environ, expect := env.Environ(), []string{"A=1", "B=2"}; !reflect.DeepEqual(environ, expect) {
+		t.Errorf("Environ: got %q but expected %q", environ, expect)
+	}
+}
+
+func TestConfigSource(t *testing.T) {
+	cases := []struct {
+		name   string
+		source *ConfigSource
+		expect *config
+		err    string
+	}{
+		{
+			name:   "valid config",
+			source: &ConfigSource{Data: []byte(`{"Name": "a", "Labels": {"b": "c"}}`)},
+			expect: &config{Name: "a", Labels: map[string]string{"b": "c"}},
+		},
+		{
+			name:   "unknown field",
+			source: &ConfigSource{Data: []byte(`{"Nmae": "a"}`)},
+			err:    `failed to decode config: json: unknown field "Nmae"`,
+		},
+		{
+			name:   "error",
+			source: &ConfigSource{Err: errors.New("not found")},
+			err:    "not found",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := &config{}
+			err := c.source.Load(cfg)
+			if c.source.Loads != 1 {
+				t.Errorf("expected 1 load, got %d", c.source.Loads)
+			}
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Errorf("expected error %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cfg, c.expect) {
+				t.Errorf("got %#v but expected %#v", cfg, c.expect)
+			}
+		})
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	FuzzArgs(f, newFlagSet)
+}
+
+func TestReloadWithConfigSource(t *testing.T) {
+	source := &ConfigSource{Data: []byte(`{"Name": "file", "Labels": {"a": "file"}}`)}
+	cfg := &config{}
+	if err := source.Load(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fs := newFlagSet()
+	ParseAndApply(t, fs, []string{"--labels=a=flag"}, cfg)
+	r, err := fs.NewReloader(cfg, func(cfg interface{}) error { return ApplyConfig(fs, cfg) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source.Data = []byte(`{"Name": "reloaded", "Labels": {"b": "file"}}`)
+	reloaded := &config{}
+	if err := source.Load(reloaded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changes, err := r.Reload(reloaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect := []legacyflag.FieldChange{{Field: "Name", Old: "file", New: "reloaded"}}
+	if !reflect.DeepEqual(changes, expect) {
+		t.Errorf("got %#v but expected %#v", changes, expect)
+	}
+	if labels := map[string]string{"a": "flag"}; !reflect.DeepEqual(reloaded.Labels, labels) {
+		t.Errorf("got labels %v but expected %v", reloaded.Labels, labels)
+	}
+}
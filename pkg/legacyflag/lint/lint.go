@@ -0,0 +1,212 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lint validates captured command lines offline, against the Schema
+// exported by a component's legacyflag.FlagSet.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+// Severity is the severity of a Finding.
+type Severity string
+
+const (
+	// Error findings are command lines the component would reject.
+	Error Severity = "error"
+	// Warning findings are command lines the component accepts, but that
+	// should be changed.
+	Warning Severity = "warning"
+)
+
+// Finding is a single problem found in a command line.
+type Finding struct {
+	Severity Severity
+	// Flag is the name of the flag the finding refers to, if any.
+	Flag    string
+	Message string
+}
+
+func (f Finding) String() string {
+	if f.Flag == "" {
+		return fmt.Sprintf("%s: %s", f.Severity, f.Message)
+	}
+	return fmt.Sprintf("%s: --%s: %s", f.Severity, f.Flag, f.Message)
+}
+
+// Lint checks args, which must not include the program name, against the
+// flags described by s. Unlike FlagSet.Parse, Lint does not stop at the first
+// problem: it reports unknown flags, invalid values, deprecated flags,
+// mutually exclusive flags that were set together, and flags that should be
+// set via the config file instead. Flag values are parsed with the same
+// parsers as the runtime.
+func Lint(s *legacyflag.Schema, args []string) ([]Finding, error) {
+	fs, err := legacyflag.NewFromSchema(s)
+	if err != nil {
+		return nil, err
+	}
+	l := &linter{
+		fs:  fs,
+		set: map[string]bool{},
+	}
+	l.lint(args)
+	l.lintSet(s)
+	return l.findings, nil
+}
+
+type linter struct {
+	fs       *legacyflag.FlagSet
+	findings []Finding
+	// set tracks the names of the flags that were set.
+	set map[string]bool
+}
+
+func (l *linter) errorf(flag, format string, a ...interface{}) {
+	l.findings = append(l.findings, Finding{Severity: Error, Flag: flag, Message: fmt.Sprintf(format, a...)})
+}
+
+func (l *linter) warnf(flag, format string, a ...interface{}) {
+	l.findings = append(l.findings, Finding{Severity: Warning, Flag: flag, Message: fmt.Sprintf(format, a...)})
+}
+
+// lint tokenizes args the same way pflag does, and checks each flag.
+func (l *linter) lint(args []string) {
+	for len(args) > 0 {
+		arg := args[0]
+		args = args[1:]
+		switch {
+		case arg == "--":
+			// the remaining arguments are positional
+			return
+		case strings.HasPrefix(arg, "--"):
+			args = l.long(arg[2:], args)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			args = l.shorthands(arg[1:], args)
+		}
+	}
+}
+
+// long checks a --name or --name=value argument, consuming the value from
+// args if necessary, and returns the remaining args.
+func (l *linter) long(s string, args []string) []string {
+	name, value, hasValue := s, "", false
+	if i := strings.Index(s, "="); i >= 0 {
+		name, value, hasValue = s[:i], s[i+1:], true
+	}
+	f := l.fs.PflagFlagSet().Lookup(name)
+	if f == nil {
+		l.errorf(name, "unknown flag")
+		return args
+	}
+	if !hasValue {
+		if f.NoOptDefVal != "" {
+			value = f.NoOptDefVal
+		} else if len(args) > 0 {
+			value, args = args[0], args[1:]
+		} else {
+			l.errorf(f.Name, "flag needs an argument")
+			return args
+		}
+	}
+	l.setFlag(f, "--"+f.Name, value)
+	return args
+}
+
+// shorthands checks a group of shorthand flags, e.g. -abc, -n5 or -n 5,
+// consuming a value from args if necessary, and returns the remaining args.
+func (l *linter) shorthands(s string, args []string) []string {
+	for len(s) > 0 {
+		c := s[:1]
+		s = s[1:]
+		f := l.fs.PflagFlagSet().ShorthandLookup(c)
+		if f == nil {
+			l.errorf("", "unknown shorthand flag: %q in -%s", c, c+s)
+			return args
+		}
+		if f.ShorthandDeprecated != "" {
+			l.warnf(f.Name, "shorthand -%s is deprecated: %s", c, f.ShorthandDeprecated)
+		}
+		var value string
+		switch {
+		case strings.HasPrefix(s, "="):
+			value, s = s[1:], ""
+		case f.NoOptDefVal != "":
+			value = f.NoOptDefVal
+		case len(s) > 0:
+			value, s = s, ""
+		case len(args) > 0:
+			value, args = args[0], args[1:]
+		default:
+			l.errorf(f.Name, "flag needs an argument: -%s", c)
+			return args
+		}
+		l.setFlag(f, "-"+c, value)
+	}
+	return args
+}
+
+// setFlag parses value with the runtime parser for f, and checks the result
+// against the constraints for f.
+func (l *linter) setFlag(f *pflag.Flag, spelling, value string) {
+	if !l.set[f.Name] {
+		if f.Deprecated != "" {
+			l.warnf(f.Name, "flag is deprecated: %s", f.Deprecated)
+		}
+		if l.fs.Metadata(f.Name).Visibility == legacyflag.VisibilityExperimental {
+			l.warnf(f.Name, "flag is experimental")
+		}
+	}
+	l.set[f.Name] = true
+	if err := f.Value.Set(value); err != nil {
+		l.errorf(f.Name, "invalid argument %q for %s: %v", value, spelling, err)
+		return
+	}
+	if err := l.fs.Metadata(f.Name).Validate(f.Value.String()); err != nil {
+		l.errorf(f.Name, "invalid argument %q for %s: %v", value, spelling, err)
+	}
+}
+
+// lintSet checks the set of flags that were set as a whole.
+func (l *linter) lintSet(s *legacyflag.Schema) {
+	for _, group := range s.MutuallyExclusive {
+		set := []string{}
+		for _, name := range group {
+			if l.set[name] {
+				set = append(set, name)
+			}
+		}
+		if len(set) > 1 {
+			l.errorf("", "flags --%s are mutually exclusive, but were set together", strings.Join(set, ", --"))
+		}
+	}
+	names := []string{}
+	for name := range l.set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if field := l.fs.Metadata(name).ConfigField; field != "" {
+			l.warnf(name, "should be set via the config file field %s", field)
+		}
+	}
+}
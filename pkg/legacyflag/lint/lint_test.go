@@ -0,0 +1,189 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/legacyflag/pkg/legacyflag"
+)
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		name   string
+		args   []string
+		expect []Finding
+	}{
+		{
+			name:   "no args",
+			args:   []string{},
+			expect: nil,
+		},
+		{
+			name:   "valid",
+			args:   []string{"--mode", "b", "--labels=a=b,c=d", "positional", "--", "--unknown"},
+			expect: nil,
+		},
+		{
+			name: "unknown flags",
+			args: []string{"--unknown=1", "-x"},
+			expect: []Finding{
+				{Severity: Error, Flag: "unknown", Message: "unknown flag"},
+				{Severity: Error, Message: "unknown shorthand flag: \"x\" in -x"},
+			},
+		},
+		{
+			name: "invalid values do not stop linting",
+			args: []string{"--port=foo", "--labels", "a", "--mode=c", "-p0"},
+			expect: []Finding{
+				{Severity: Error, Flag: "port", Message: "invalid argument \"foo\" for --port: strconv.ParseInt: parsing \"foo\": invalid syntax"},
+				{Severity: Error, Flag: "labels", Message: "invalid argument \"a\" for --labels: malformed pair, expect string=string"},
+				{Severity: Error, Flag: "mode", Message: "invalid argument \"c\" for --mode: must be one of a, b"},
+				{Severity: Error, Flag: "port", Message: "invalid argument \"0\" for -p: must be in range [1, 65535]"},
+				{Severity: Warning, Flag: "port", Message: "should be set via the config file field Port"},
+			},
+		},
+		{
+			name: "deprecated flags and shorthands",
+			args: []string{"--old=x", "--old=y", "-d"},
+			expect: []Finding{
+				{Severity: Warning, Flag: "old", Message: "flag is deprecated: Use --mode instead."},
+				{Severity: Warning, Flag: "debug", Message: "shorthand -d is deprecated: Use --debug instead."},
+			},
+		},
+		{
+			name: "experimental flags",
+			args: []string{"--new-thing", "--new-thing=false"},
+			expect: []Finding{
+				{Severity: Warning, Flag: "new-thing", Message: "flag is experimental"},
+			},
+		},
+		{
+			name: "mutually exclusive",
+			args: []string{"--old=x", "--mode=a"},
+			expect: []Finding{
+				{Severity: Warning, Flag: "old", Message: "flag is deprecated: Use --mode instead."},
+				{Severity: Error, Message: "flags --old, --mode are mutually exclusive, but were set together"},
+			},
+		},
+		{
+			name: "missing argument",
+			args: []string{"--mode"},
+			expect: []Finding{
+				{Severity: Error, Flag: "mode", Message: "flag needs an argument"},
+			},
+		},
+	}
+	fs := legacyflag.NewFlagSet("")
+	fs.IntVar("port", 80, "")
+	fs.BoolVar("debug", false, "")
+	fs.StringVar("mode", "a", "")
+	fs.StringVar("old", "", "")
+	fs.WithVisibility(legacyflag.VisibilityExperimental).BoolVar("new-thing", false, "")
+	fs.MapStringStringVar("labels", nil, "", &legacyflag.MapOptions{})
+	fs.PflagFlagSet().Lookup("port").Shorthand = "p"
+	fs.PflagFlagSet().Lookup("debug").Shorthand = "d"
+	for _, err := range []error{
+		fs.MarkConfigField("port", "Port"),
+		fs.MarkRange("port", 1, 65535),
+		fs.MarkEnum("mode", "a", "b"),
+		fs.MarkDeprecated("old", "Use --mode instead."),
+		fs.MarkMutuallyExclusive("old", "mode"),
+		fs.PflagFlagSet().MarkShorthandDeprecated("debug", "Use --debug instead."),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	b, err := fs.Schema()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := &legacyflag.Schema{}
+	if err := json.Unmarshal(b, s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			findings, err := Lint(s, c.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(findings, c.expect) {
+				t.Errorf("got %#v but expected %#v", findings, c.expect)
+			}
+		})
+	}
+}
+
+func TestArgsFromManifest(t *testing.T) {
+	pod := `
+apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - name: kubelet
+    command: ["kubelet", "--port=10250"]
+    args: ["--debug"]
+`
+	daemonSet := `
+{
+  "apiVersion": "apps/v1",
+  "kind": "DaemonSet",
+  "spec": {
+    "template": {
+      "spec": {
+        "initContainers": [{"name": "init", "args": ["--init"]}],
+        "containers": [{"name": "proxy", "args": ["--mode=b"]}]
+      }
+    }
+  }
+}
+`
+	cases := []struct {
+		name      string
+		manifest  string
+		container string
+		expect    []string
+		err       string
+	}{
+		{"pod", pod, "", []string{"--port=10250", "--debug"}, ""},
+		{"pod with container name", pod, "kubelet", []string{"--port=10250", "--debug"}, ""},
+		{"pod template", daemonSet, "proxy", []string{"--mode=b"}, ""},
+		{"init container", daemonSet, "init", []string{"--init"}, ""},
+		{"ambiguous container", daemonSet, "", nil, "manifest contains 2 containers, container name must be specified"},
+		{"missing container", pod, "missing", nil, "container \"missing\" not found in manifest"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			args, err := ArgsFromManifest([]byte(c.manifest), c.container)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("expect error %s but got %v", c.err, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(args, c.expect) {
+				t.Errorf("got %#v but expected %#v", args, c.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// manifest contains the fields of a Kubernetes workload manifest that hold
+// containers. Pods keep containers in spec, and workload controllers in the
+// spec of their pod template.
+type manifest struct {
+	Spec struct {
+		podSpec
+		Template struct {
+			Spec podSpec `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+type podSpec struct {
+	InitContainers []container `json:"initContainers"`
+	Containers     []container `json:"containers"`
+}
+
+type container struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+	Args    []string `json:"args"`
+}
+
+// ArgsFromManifest returns the command line arguments, excluding the program
+// name, of the named container in a Kubernetes manifest. data may be YAML or
+// JSON, and contain a Pod or a workload with a pod template. If name is
+// empty, the manifest must contain exactly one container.
+func ArgsFromManifest(data []byte, name string) ([]string, error) {
+	m := &manifest{}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %v", err)
+	}
+	containers := []container{}
+	for _, s := range []podSpec{m.Spec.podSpec, m.Spec.Template.Spec} {
+		containers = append(containers, s.InitContainers...)
+		containers = append(containers, s.Containers...)
+	}
+
+	var c *container
+	switch {
+	case name != "":
+		for i := range containers {
+			if containers[i].Name == name {
+				c = &containers[i]
+				break
+			}
+		}
+		if c == nil {
+			return nil, fmt.Errorf("container %q not found in manifest", name)
+		}
+	case len(containers) == 1:
+		c = &containers[0]
+	default:
+		return nil, fmt.Errorf("manifest contains %d containers, container name must be specified", len(containers))
+	}
+
+	// the first element of command is the program, and args follow command
+	args := []string{}
+	if len(c.Command) > 0 {
+		args = append(args, c.Command[1:]...)
+	}
+	return append(args, c.Args...), nil
+}
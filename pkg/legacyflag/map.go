@@ -24,16 +24,18 @@ type MapOptions struct {
 	// DisableCommaSeparatedPairs disables parsing multiple comma-separated
 	// key-value pairs from a single invocation. Instead, the entire string
 	// after the = separator will be parsed as the value. This can be convenient
-	// if values contain commas.
-	DisableCommaSeparatedPairs bool
+	// if values contain commas. Since the flag value's String still joins
+	// the pairs with PairSep, it can't be passed back to a single Set call if
+	// the map has more than one pair.
+	DisableCommaSeparatedPairs bool `json:"disableCommaSeparatedPairs,omitempty"`
 
 	// KeyValueSep is the separator between a key and its corresponding value.
 	// Default: equals sign (=).
-	KeyValueSep string
+	KeyValueSep string `json:"keyValueSep"`
 
 	// PairSep is the separator between key-value pairs.
 	// Default: comma (,).
-	PairSep string
+	PairSep string `json:"pairSep"`
 }
 
 // Default applies defaults to uninitialized values in MapOptions.
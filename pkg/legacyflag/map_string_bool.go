@@ -40,6 +40,12 @@ type MapStringBoolValue struct {
 // Multiple flag invocations are supported.
 // Example usage: `--flag "a=true" --flag "b=false"`.
 func (fs *FlagSet) MapStringBoolVar(name string, def map[string]bool, usage string, options *MapOptions) *MapStringBoolValue {
+	return fs.MapStringBoolVarP(name, "", def, usage, options)
+}
+
+// MapStringBoolVarP is like MapStringBoolVar, but accepts a shorthand
+// letter that can be used after a single dash.
+func (fs *FlagSet) MapStringBoolVarP(name, shorthand string, def map[string]bool, usage string, options *MapOptions) *MapStringBoolValue {
 	val := &MapStringBoolValue{
 		name:  name,
 		value: make(map[string]bool),
@@ -48,7 +54,8 @@ func (fs *FlagSet) MapStringBoolVar(name string, def map[string]bool, usage stri
 	for k, v := range def {
 		val.value[k] = v
 	}
-	fs.fs.Var(newMapStringBool(&val.value, options), name, usage)
+	fs.fs.VarP(newMapStringBool(&val.value, options), name, shorthand, usage)
+	fs.register(name, val)
 	return val
 }
 
@@ -84,6 +91,11 @@ func (v *MapStringBoolValue) Apply(apply func(value map[string]bool)) {
 	}
 }
 
+// goType implements goTyper.
+func (v *MapStringBoolValue) goType() string {
+	return "map[string]bool"
+}
+
 // mapStringBool implements pflag.Value for map[string]bool
 type mapStringBool struct {
 	m           *map[string]bool
@@ -19,6 +19,8 @@ package legacyflag
 import (
 	"reflect"
 	"testing"
+
+	"github.com/spf13/pflag"
 )
 
 func TestMapStringBoolVar(t *testing.T) {
@@ -52,6 +54,24 @@ func TestMapStringBoolVar(t *testing.T) {
 			},
 			apply: true,
 		},
+		{
+			name: "flag is set with shorthand",
+			args: []string{"-f=one=false,bar=true"},
+			target: map[string]bool{
+				"foo": true,
+				"bar": false,
+			},
+			set: map[string]bool{
+				"one": false,
+				"bar": true,
+			},
+			merge: map[string]bool{
+				"one": false,
+				"foo": true,
+				"bar": true,
+			},
+			apply: true,
+		},
 		{
 			name: "flag is not set",
 			args: []string{""},
@@ -74,7 +94,7 @@ func TestMapStringBoolVar(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			fs := NewFlagSet("")
-			val := fs.MapStringBoolVar("foo", c.target, "", &MapOptions{})
+			val := fs.MapStringBoolVarP("foo", "f", c.target, "", &MapOptions{})
 			if err := fs.Parse(c.args); err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -318,3 +338,12 @@ func copyMapStringBool(m map[string]bool) map[string]bool {
 	}
 	return n
 }
+
+func FuzzMapStringBool(f *testing.F) {
+	f.Fuzz(func(t *testing.T, value string, disableCommaSeparatedPairs bool, seps uint8) {
+		fuzzMap(t, func(o *MapOptions) (pflag.Value, func() interface{}) {
+			m := map[string]bool{}
+			return newMapStringBool(&m, o), func() interface{} { return m }
+		}, value, fuzzMapOptions(disableCommaSeparatedPairs, seps))
+	})
+}
@@ -40,6 +40,12 @@ type MapStringStringValue struct {
 // Multiple flag invocations are supported.
 // For example: `--flag "a=foo" --flag "b=bar"`.
 func (fs *FlagSet) MapStringStringVar(name string, def map[string]string, usage string, options *MapOptions) *MapStringStringValue {
+	return fs.MapStringStringVarP(name, "", def, usage, options)
+}
+
+// MapStringStringVarP is like MapStringStringVar, but accepts a shorthand
+// letter that can be used after a single dash.
+func (fs *FlagSet) MapStringStringVarP(name, shorthand string, def map[string]string, usage string, options *MapOptions) *MapStringStringValue {
 	val := &MapStringStringValue{
 		name:  name,
 		value: make(map[string]string),
@@ -48,7 +54,8 @@ func (fs *FlagSet) MapStringStringVar(name string, def map[string]string, usage
 	for k, v := range def {
 		val.value[k] = v
 	}
-	fs.fs.Var(newMapStringString(&val.value, options), name, usage)
+	fs.fs.VarP(newMapStringString(&val.value, options), name, shorthand, usage)
+	fs.register(name, val)
 	return val
 }
 
@@ -84,6 +91,11 @@ func (v *MapStringStringValue) Apply(apply func(value map[string]string)) {
 	}
 }
 
+// goType implements goTyper.
+func (v *MapStringStringValue) goType() string {
+	return "map[string]string"
+}
+
 // mapStringString implements plfag.Value for map[string]string
 type mapStringString struct {
 	m           *map[string]string
@@ -19,6 +19,8 @@ package legacyflag
 import (
 	"reflect"
 	"testing"
+
+	"github.com/spf13/pflag"
 )
 
 func TestMapStringStringVar(t *testing.T) {
@@ -52,6 +54,24 @@ func TestMapStringStringVar(t *testing.T) {
 			},
 			apply: true,
 		},
+		{
+			name: "flag is set with shorthand",
+			args: []string{"-f=one=quux,bar=baz"},
+			target: map[string]string{
+				"foo": "baz",
+				"bar": "quux",
+			},
+			set: map[string]string{
+				"one": "quux",
+				"bar": "baz",
+			},
+			merge: map[string]string{
+				"one": "quux",
+				"foo": "baz",
+				"bar": "baz",
+			},
+			apply: true,
+		},
 		{
 			name: "flag is not set",
 			args: []string{""},
@@ -74,7 +94,7 @@ func TestMapStringStringVar(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			fs := NewFlagSet("")
-			val := fs.MapStringStringVar("foo", c.target, "", &MapOptions{})
+			val := fs.MapStringStringVarP("foo", "f", c.target, "", &MapOptions{})
 			if err := fs.Parse(c.args); err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -314,3 +334,12 @@ func copyMapStringString(m map[string]string) map[string]string {
 	}
 	return n
 }
+
+func FuzzMapStringString(f *testing.F) {
+	f.Fuzz(func(t *testing.T, value string, disableCommaSeparatedPairs bool, seps uint8) {
+		fuzzMap(t, func(o *MapOptions) (pflag.Value, func() interface{}) {
+			m := map[string]string{}
+			return newMapStringString(&m, o), func() interface{} { return m }
+		}, value, fuzzMapOptions(disableCommaSeparatedPairs, seps))
+	})
+}
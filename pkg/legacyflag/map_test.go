@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// fuzzKeyValueSeps and fuzzPairSeps are the separators covered by the map
+// fuzz tests. Pair separators are single characters, since a multi-character
+// pair separator is ambiguous if a value ends with a prefix of it.
+var (
+	fuzzKeyValueSeps = []string{"=", ":", "->"}
+	fuzzPairSeps     = []string{",", ";", " "}
+)
+
+// fuzzMapOptions returns the MapOptions combination selected by the fuzz
+// inputs.
+func fuzzMapOptions(disableCommaSeparatedPairs bool, seps uint8) *MapOptions {
+	return &MapOptions{
+		DisableCommaSeparatedPairs: disableCommaSeparatedPairs,
+		KeyValueSep:                fuzzKeyValueSeps[int(seps)%len(fuzzKeyValueSeps)],
+		PairSep:                    fuzzPairSeps[int(seps)/len(fuzzKeyValueSeps)%len(fuzzPairSeps)],
+	}
+}
+
+// newMapFunc returns a new map flag value for the options, and a func that
+// returns the parsed map.
+type newMapFunc func(o *MapOptions) (pflag.Value, func() interface{})
+
+// fuzzMap checks the parsing properties of a map flag value for the input,
+// which is split at newlines into the values of successive Set calls: Set
+// doesn't panic, keys and string values are trimmed, and if the input is
+// valid, Set(String()) reproduces the parsed map. With
+// DisableCommaSeparatedPairs, String can't be parsed back if the map has
+// more than one pair, so each pair is set again separately instead.
+func fuzzMap(t *testing.T, newMap newMapFunc, value string, o *MapOptions) {
+	v, get := newMap(o)
+	for _, s := range strings.Split(value, "\n") {
+		if err := v.Set(s); err != nil {
+			return
+		}
+	}
+	parsed := get()
+
+	m := reflect.ValueOf(parsed)
+	for _, k := range m.MapKeys() {
+		if s := k.String(); s != strings.TrimSpace(s) {
+			t.Errorf("Set(%q): key %q is not trimmed", value, s)
+		}
+		if e := m.MapIndex(k); e.Kind() == reflect.String && e.String() != strings.TrimSpace(e.String()) {
+			t.Errorf("Set(%q): value %q is not trimmed", value, e.String())
+		}
+	}
+
+	s := v.String()
+	reparsed, get := newMap(o)
+	if o.DisableCommaSeparatedPairs && m.Len() > 1 {
+		if err := reparsed.Set(s); err == nil && reflect.ValueOf(get()).Len() != 1 {
+			t.Errorf("Set(%q): Set(String()) = %#v, expected a single pair", value, get())
+		}
+		reparsed, get = newMap(o)
+		for _, k := range m.MapKeys() {
+			pair := fmt.Sprintf("%s%s%v", k.String(), o.KeyValueSep, m.MapIndex(k))
+			if err := reparsed.Set(pair); err != nil {
+				t.Fatalf("Set(%q): pair %q does not parse: %v", value, pair, err)
+			}
+		}
+		if !reflect.DeepEqual(get(), parsed) {
+			t.Errorf("Set(%q): setting each pair = %#v, expected %#v", value, get(), parsed)
+		}
+		return
+	}
+	if err := reparsed.Set(s); err != nil {
+		t.Fatalf("Set(%q): String() = %q does not parse: %v", value, s, err)
+	}
+	if !reflect.DeepEqual(get(), parsed) {
+		t.Errorf("Set(%q): Set(String()) = %#v, expected %#v", value, get(), parsed)
+	}
+	if again := reparsed.String(); again != s {
+		t.Errorf("Set(%q): String() is not stable: %q, then %q", value, s, again)
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Metadata contains legacyflag-specific information about a registered flag,
+// beyond what pflag tracks.
+type Metadata struct {
+	// ConfigField is the path of the ComponentConfig field the flag maps to,
+	// e.g. "Authentication.Anonymous.Enabled". Empty if the flag has no
+	// config file equivalent.
+	ConfigField string
+
+	// Section is the title of the usage section the flag is listed in. Empty
+	// for the default section.
+	Section string
+
+	// Enum lists the values the flag accepts. Empty if unconstrained.
+	Enum []string
+
+	// Keys lists the known keys of a map flag, for shell completion.
+	Keys []string
+
+	// Filename is true if the flag value is a file path, for shell completion.
+	// FileExtensions optionally restricts completion to files with the given
+	// extensions, e.g. "yaml".
+	Filename       bool
+	FileExtensions []string
+
+	// Min and Max bound the value of a numeric flag, inclusively. Both are nil
+	// if the flag is unbounded.
+	Min *float64
+	Max *float64
+
+	// Visibility is the visibility level of the flag.
+	Visibility Visibility
+
+	// Sensitive is true if the flag value must not be exposed, e.g. because
+	// it is a credential. Diff redacts the value.
+	Sensitive bool
+}
+
+// Metadata returns a copy of the legacyflag metadata for the named flag.
+func (fs *FlagSet) Metadata(name string) Metadata {
+	m, ok := fs.meta[fs.canonical(name)]
+	if !ok {
+		return Metadata{}
+	}
+	c := *m
+	c.Enum = append([]string(nil), m.Enum...)
+	c.Keys = append([]string(nil), m.Keys...)
+	c.FileExtensions = append([]string(nil), m.FileExtensions...)
+	return c
+}
+
+// flagMetadata returns the metadata for the named flag, which may be nil. The
+// name is canonicalized, and an alias resolves to the flag it refers to.
+func (fs *FlagSet) flagMetadata(name string) *Metadata {
+	name = fs.canonical(name)
+	for _, a := range fs.aliases {
+		if a.old == name {
+			name = a.new
+			break
+		}
+	}
+	return fs.meta[name]
+}
+
+// metadata returns the mutable metadata for the named flag, or an error if
+// the flag does not exist.
+func (fs *FlagSet) metadata(name string) (*Metadata, error) {
+	if fs.fs.Lookup(name) == nil {
+		return nil, fmt.Errorf("flag %q does not exist", name)
+	}
+	name = fs.canonical(name)
+	m, ok := fs.meta[name]
+	if !ok {
+		m = &Metadata{}
+		fs.meta[name] = m
+	}
+	return m, nil
+}
+
+// MarkConfigField records the path of the ComponentConfig field that the
+// named flag maps to.
+func (fs *FlagSet) MarkConfigField(name, field string) error {
+	m, err := fs.metadata(name)
+	if err != nil {
+		return err
+	}
+	m.ConfigField = field
+	return nil
+}
+
+// MarkSection assigns the named flag to a usage section. This is useful for
+// flags that were not registered through a view returned by Section.
+func (fs *FlagSet) MarkSection(name, section string) error {
+	m, err := fs.metadata(name)
+	if err != nil {
+		return err
+	}
+	if section != "" {
+		fs.addSection(section)
+	}
+	m.Section = section
+	return nil
+}
+
+// MarkSensitive records that the value of the named flag must not be
+// exposed, e.g. in logs.
+func (fs *FlagSet) MarkSensitive(name string) error {
+	m, err := fs.metadata(name)
+	if err != nil {
+		return err
+	}
+	m.Sensitive = true
+	return nil
+}
+
+// MarkMapKeys records the known keys of the named map flag. Shell completion
+// offers these keys when completing the flag value.
+func (fs *FlagSet) MarkMapKeys(name string, keys ...string) error {
+	m, err := fs.metadata(name)
+	if err != nil {
+		return err
+	}
+	if mapOptions(fs.fs.Lookup(name).Value) == nil {
+		return fmt.Errorf("flag %q is not a map flag", name)
+	}
+	m.Keys = append([]string(nil), keys...)
+	return nil
+}
+
+// MarkFilename records that the value of the named flag is a file path.
+// Shell completion completes file paths for the flag value, restricted to the
+// given extensions, if any.
+func (fs *FlagSet) MarkFilename(name string, extensions ...string) error {
+	m, err := fs.metadata(name)
+	if err != nil {
+		return err
+	}
+	m.Filename = true
+	m.FileExtensions = append([]string(nil), extensions...)
+	return nil
+}
+
+// MarkEnum records the values the named flag accepts, for documentation and
+// offline validation, see Metadata.Validate. Parse does not enforce them.
+// Slice and map flags can't be marked, since their values are not checked
+// element by element.
+func (fs *FlagSet) MarkEnum(name string, values ...string) error {
+	m, err := fs.metadata(name)
+	if err != nil {
+		return err
+	}
+	if t := fs.fs.Lookup(name).Value.Type(); !isScalar(t) {
+		return fmt.Errorf("flag %q has non-scalar type %s", name, t)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("allowed values for flag %q must be set", name)
+	}
+	m.Enum = append([]string(nil), values...)
+	return nil
+}
+
+// MarkRange records that the named numeric flag accepts values in the closed
+// interval [min, max], for documentation and offline validation, see
+// Metadata.Validate. Parse does not enforce the range.
+func (fs *FlagSet) MarkRange(name string, min, max float64) error {
+	m, err := fs.metadata(name)
+	if err != nil {
+		return err
+	}
+	if t := fs.fs.Lookup(name).Value.Type(); !isNumeric(t) {
+		return fmt.Errorf("flag %q has non-numeric type %s", name, t)
+	}
+	if min > max {
+		return fmt.Errorf("invalid range for flag %q: min %v is greater than max %v", name, min, max)
+	}
+	m.Min = &min
+	m.Max = &max
+	return nil
+}
+
+// MarkMutuallyExclusive declares that at most one of the named flags may be
+// set. Parse returns an error if more than one of them is set.
+func (fs *FlagSet) MarkMutuallyExclusive(names ...string) error {
+	if len(names) < 2 {
+		return fmt.Errorf("mutually exclusive group must contain at least two flags, got %v", names)
+	}
+	for _, name := range names {
+		if fs.fs.Lookup(name) == nil {
+			return fmt.Errorf("flag %q does not exist", name)
+		}
+	}
+	group := make([]string, len(names))
+	for i, name := range names {
+		group[i] = fs.canonical(name)
+	}
+	fs.exclusive = append(fs.exclusive, group)
+	return nil
+}
+
+// changed returns the names of the flags in names that were set.
+func (fs *FlagSet) changed(names []string) []string {
+	set := []string{}
+	for _, name := range names {
+		if fs.fs.Changed(name) {
+			set = append(set, name)
+		}
+	}
+	return set
+}
+
+// exclusiveError returns the error for mutually exclusive flags that were
+// set together.
+func exclusiveError(set []string) error {
+	return fmt.Errorf("flags --%s are mutually exclusive, but were set together", strings.Join(set, ", --"))
+}
+
+// Validate checks the flags that were set against the mutually exclusive
+// groups and the experimental flag policy. Parse calls Validate, but callers
+// that parse the flags with another parser, e.g. a command line framework,
+// must call it themselves. Validate also marks flags that were set using an
+// alias as set, see Alias.
+func (fs *FlagSet) Validate() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.validate()
+}
+
+// validate implements Validate. The caller must hold the write lock.
+func (fs *FlagSet) validate() error {
+	if err := fs.resolveAliases(); err != nil {
+		return err
+	}
+	for _, group := range fs.exclusive {
+		if set := fs.changed(group); len(set) > 1 {
+			return exclusiveError(set)
+		}
+	}
+	return fs.checkExperimental()
+}
+
+// Validate checks the string representation of a flag value against the
+// enum and range constraints in m.
+func (m Metadata) Validate(value string) error {
+	if len(m.Enum) > 0 {
+		found := false
+		for _, e := range m.Enum {
+			if value == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("must be one of %s", strings.Join(m.Enum, ", "))
+		}
+	}
+	if m.Min != nil && m.Max != nil {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		if f < *m.Min || f > *m.Max {
+			return fmt.Errorf("must be in range [%v, %v]", *m.Min, *m.Max)
+		}
+	}
+	return nil
+}
+
+// isNumeric returns true if the pflag type name t is a numeric type.
+// isScalar returns whether the pflag type t holds a single value, rather than
+// a slice or a map.
+func isScalar(t string) bool {
+	return !strings.HasSuffix(t, "Slice") && !strings.HasPrefix(t, "map")
+}
+
+func isNumeric(t string) bool {
+	switch t {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.StringVar("mode", "", "")
+	fs.IntVar("port", 0, "")
+	fs.StringSliceVar("modes", nil, "")
+
+	if err := fs.MarkConfigField("port", "Server.Port"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.MarkEnum("mode", "a", "b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.MarkRange("port", 1, 65535); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m := fs.Metadata("mode"); !reflect.DeepEqual(m, Metadata{Enum: []string{"a", "b"}}) {
+		t.Errorf("got %#v for mode", m)
+	}
+	min, max := 1.0, 65535.0
+	if m := fs.Metadata("port"); !reflect.DeepEqual(m, Metadata{ConfigField: "Server.Port", Min: &min, Max: &max}) {
+		t.Errorf("got %#v for port", m)
+	}
+	if m := fs.Metadata("unknown"); !reflect.DeepEqual(m, Metadata{}) {
+		t.Errorf("got %#v for unknown", m)
+	}
+
+	// returned metadata is a copy
+	fs.Metadata("mode").Enum[0] = "c"
+	if m := fs.Metadata("mode"); m.Enum[0] != "a" {
+		t.Errorf("Metadata returned a reference to internal state")
+	}
+
+	if err := fs.MarkConfigField("unknown", "Foo"); err == nil {
+		t.Errorf("expect error for unknown flag")
+	}
+	if err := fs.MarkEnum("mode"); err == nil {
+		t.Errorf("expect error for empty enum")
+	}
+	if err := fs.MarkEnum("modes", "a", "b"); err == nil {
+		t.Errorf("expect error for enum on slice flag")
+	}
+	if err := fs.MarkRange("mode", 0, 1); err == nil {
+		t.Errorf("expect error for range on non-numeric flag")
+	}
+	if err := fs.MarkRange("port", 1, 0); err == nil {
+		t.Errorf("expect error for inverted range")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name  string
+		flag  string
+		value string
+		err   string
+	}{
+		{
+			name:  "enum satisfied",
+			flag:  "mode",
+			value: "b",
+		},
+		{
+			name:  "range satisfied",
+			flag:  "port",
+			value: "65535",
+		},
+		{
+			name:  "enum violated",
+			flag:  "mode",
+			value: "c",
+			err:   "must be one of a, b",
+		},
+		{
+			name:  "range violated",
+			flag:  "port",
+			value: "0",
+			err:   "must be in range [1, 65535]",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := NewFlagSet("")
+			fs.StringVar("mode", "", "")
+			fs.IntVar("port", 0, "")
+			if err := fs.MarkEnum("mode", "a", "b"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := fs.MarkRange("port", 1, 65535); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// constraints are not enforced by Parse
+			if err := fs.Parse([]string{"--" + c.flag + "=" + c.value}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			err := fs.Metadata(c.flag).Validate(c.value)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("expect error %s but got %v", c.err, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestMarkMutuallyExclusive(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.BoolVar("a", false, "")
+	fs.BoolVar("b", false, "")
+	if err := fs.MarkMutuallyExclusive("a"); err == nil {
+		t.Errorf("expect error for group with one flag")
+	}
+	if err := fs.MarkMutuallyExclusive("a", "missing"); err == nil {
+		t.Errorf("expect error for unknown flag")
+	}
+	if err := fs.MarkMutuallyExclusive("a", "b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Parse([]string{"--b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect := "flags --a, --b are mutually exclusive, but were set together"
+	if err := fs.Parse([]string{"--a"}); err == nil || err.Error() != expect {
+		t.Errorf("expect error %s but got %v", expect, err)
+	}
+}
@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,38 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
-	"github.com/spf13/pflag"
+import (
 	"net"
+
+	"github.com/spf13/pflag"
 )
 
 // IPValue is a reference to a registered net.IP flag value.
 type IPValue struct {
-	name string
+	name  string
 	value net.IP
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // IPVar registers a flag for net.IP against the FlagSet, and returns
 // a IPValue reference to the registered flag value.
 func (fs *FlagSet) IPVar(name string, def net.IP, usage string) *IPValue {
+	return fs.IPVarP(name, "", def, usage)
+}
+
+// IPVarP is like IPVar, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) IPVarP(name, shorthand string, def net.IP, usage string) *IPValue {
 	v := &IPValue{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.IPVar(&v.value, name, def, usage)
+	fs.fs.IPVarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -54,3 +62,8 @@ func (v *IPValue) Apply(apply func(value net.IP)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *IPValue) goType() string {
+	return "net.IP"
+}
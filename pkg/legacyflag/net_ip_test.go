@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,33 +14,70 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
-	"testing"
-	"reflect"
+import (
+	"io/ioutil"
 	"net"
+	"reflect"
+	"strings"
+	"testing"
 )
 
 func TestIPVar(t *testing.T) {
 	cases := []struct {
-		name string
-		args []string
+		name  string
+		args  []string
+		def   net.IP
 		set   net.IP
 		apply bool
+		err   string
 	}{
 		{
-			name: "flag is set",
-			args: []string{"--foo=192.0.2.1"},
-			set: net.ParseIP("192.0.2.1"),
+			name:  "flag is set",
+			args:  []string{"--foo=192.0.2.1"},
+			set:   net.ParseIP("192.0.2.1"),
+			apply: true,
+		},
+		{
+			name:  "flag is set with shorthand",
+			args:  []string{"-f=192.0.2.1"},
+			set:   net.ParseIP("192.0.2.1"),
+			apply: true,
+		},
+		{
+			name:  "flag is set with alias",
+			args:  []string{"--old-foo=192.0.2.1"},
+			set:   net.ParseIP("192.0.2.1"),
 			apply: true,
 		},
 		{
 			name: "flag is not set",
-			args: []string{""},
-			apply: false,
+			args: []string{},
+		},
+		{
+			name: "default is not copied when flag is not set",
+			args: []string{},
+			def:  net.ParseIP("127.0.0.1"),
+		},
+		{
+			name:  "IPv6",
+			args:  []string{"--foo=2001:db8::1"},
+			set:   net.ParseIP("2001:db8::1"),
+			apply: true,
+		},
+		{
+			name:  "value is trimmed",
+			args:  []string{"--foo= 192.0.2.1 "},
+			set:   net.ParseIP("192.0.2.1"),
+			apply: true,
+		},
+		{
+			name: "invalid value",
+			args: []string{"--foo=192.0.2"},
+			err:  "failed to parse IP: \"192.0.2\"",
 		},
 	}
 
@@ -49,8 +86,19 @@ func TestIPVar(t *testing.T) {
 			var target net.IP
 
 			fs := NewFlagSet("")
-			val := fs.IPVar("foo", target, "")
-			if err := fs.Parse(c.args); err != nil {
+			val := fs.IPVarP("foo", "f", c.def, "")
+			fs.PflagFlagSet().SetOutput(ioutil.Discard)
+			if err := fs.Alias("old-foo", "foo", ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
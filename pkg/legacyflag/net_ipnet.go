@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,38 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
-	"github.com/spf13/pflag"
+import (
 	"net"
+
+	"github.com/spf13/pflag"
 )
 
 // IPNetValue is a reference to a registered net.IPNet flag value.
 type IPNetValue struct {
-	name string
+	name  string
 	value net.IPNet
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // IPNetVar registers a flag for net.IPNet against the FlagSet, and returns
 // a IPNetValue reference to the registered flag value.
 func (fs *FlagSet) IPNetVar(name string, def net.IPNet, usage string) *IPNetValue {
+	return fs.IPNetVarP(name, "", def, usage)
+}
+
+// IPNetVarP is like IPNetVar, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) IPNetVarP(name, shorthand string, def net.IPNet, usage string) *IPNetValue {
 	v := &IPNetValue{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.IPNetVar(&v.value, name, def, usage)
+	fs.fs.IPNetVarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -54,3 +62,8 @@ func (v *IPNetValue) Apply(apply func(value net.IPNet)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *IPNetValue) goType() string {
+	return "net.IPNet"
+}
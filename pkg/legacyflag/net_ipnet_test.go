@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,33 +14,76 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
-	"testing"
-	"reflect"
+import (
+	"io/ioutil"
 	"net"
+	"reflect"
+	"strings"
+	"testing"
 )
 
 func TestIPNetVar(t *testing.T) {
 	cases := []struct {
-		name string
-		args []string
+		name  string
+		args  []string
+		def   net.IPNet
 		set   net.IPNet
 		apply bool
+		err   string
 	}{
 		{
-			name: "flag is set",
-			args: []string{"--foo=192.0.2.1/24"},
-			set: func() net.IPNet {_, n, _ := net.ParseCIDR("192.0.2.1/24"); return *n}(),
+			name:  "flag is set",
+			args:  []string{"--foo=192.0.2.1/24"},
+			set:   func() net.IPNet { _, n, _ := net.ParseCIDR("192.0.2.1/24"); return *n }(),
+			apply: true,
+		},
+		{
+			name:  "flag is set with shorthand",
+			args:  []string{"-f=192.0.2.1/24"},
+			set:   func() net.IPNet { _, n, _ := net.ParseCIDR("192.0.2.1/24"); return *n }(),
+			apply: true,
+		},
+		{
+			name:  "flag is set with alias",
+			args:  []string{"--old-foo=192.0.2.1/24"},
+			set:   func() net.IPNet { _, n, _ := net.ParseCIDR("192.0.2.1/24"); return *n }(),
 			apply: true,
 		},
 		{
 			name: "flag is not set",
-			args: []string{""},
-			apply: false,
+			args: []string{},
+		},
+		{
+			name: "default is not copied when flag is not set",
+			args: []string{},
+			def:  net.IPNet{IP: net.IPv4(127, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)},
+		},
+		{
+			name:  "IPv4 address is normalized to the network",
+			args:  []string{"--foo=192.0.2.1/24"},
+			set:   net.IPNet{IP: net.IPv4(192, 0, 2, 0).To4(), Mask: net.CIDRMask(24, 32)},
+			apply: true,
+		},
+		{
+			name:  "IPv6 address is normalized to the network",
+			args:  []string{"--foo=2001:db8::1/64"},
+			set:   net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(64, 128)},
+			apply: true,
+		},
+		{
+			name:  "repeated flag, last wins",
+			args:  []string{"--foo=192.0.2.0/24", "--foo=198.51.100.0/24"},
+			set:   net.IPNet{IP: net.IPv4(198, 51, 100, 0).To4(), Mask: net.CIDRMask(24, 32)},
+			apply: true,
+		},
+		{
+			name: "missing prefix length",
+			args: []string{"--foo=192.0.2.1"},
+			err:  "invalid argument \"192.0.2.1\" for \"-f, --foo\" flag",
 		},
 	}
 
@@ -49,8 +92,19 @@ func TestIPNetVar(t *testing.T) {
 			var target net.IPNet
 
 			fs := NewFlagSet("")
-			val := fs.IPNetVar("foo", target, "")
-			if err := fs.Parse(c.args); err != nil {
+			val := fs.IPNetVarP("foo", "f", c.def, "")
+			fs.PflagFlagSet().SetOutput(ioutil.Discard)
+			if err := fs.Alias("old-foo", "foo", ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
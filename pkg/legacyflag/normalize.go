@@ -0,0 +1,153 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// NormalizePolicy configures how flag names are normalized. Names are
+// normalized wherever the FlagSet accepts them: when flags are registered,
+// looked up, marked, imported from the global flagsets and parsed. Every
+// spelling that normalizes to the same name refers to the same flag, and the
+// normalized name is the flag's canonical name, e.g. in usage.
+type NormalizePolicy struct {
+	// UnderscoreHyphen treats underscores as hyphens, e.g. --log_dir is
+	// --log-dir.
+	UnderscoreHyphen bool
+	// FoldCase treats names case-insensitively. Canonical names are lower
+	// case.
+	FoldCase bool
+	// Aliases maps legacy names to the names of the flags they refer to. Keys
+	// and values are normalized with the rules above first.
+	Aliases map[string]string
+	// Warnings, if non-nil, receives a warning from Parse for every flag that
+	// was set using a spelling other than its canonical name.
+	Warnings io.Writer
+}
+
+// normalizer implements a NormalizePolicy.
+type normalizer struct {
+	policy NormalizePolicy
+	// aliases holds the normalized Aliases.
+	aliases map[string]string
+}
+
+// fold applies the UnderscoreHyphen and FoldCase rules.
+func (n *normalizer) fold(name string) string {
+	if n.policy.UnderscoreHyphen {
+		name = strings.Replace(name, "_", "-", -1)
+	}
+	if n.policy.FoldCase {
+		name = strings.ToLower(name)
+	}
+	return name
+}
+
+// normalize returns the canonical spelling of name.
+func (n *normalizer) normalize(name string) string {
+	name = n.fold(name)
+	if alias, ok := n.aliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// SetNormalizePolicy sets the policy used to normalize flag names, using the
+// normalization func of the underlying pflag.FlagSet. Flags that were already
+// registered are renamed to their canonical names. Names are not normalized
+// when parsing with a Go flag.FlagSet frontend, see NewFromGoFlagSet.
+func (fs *FlagSet) SetNormalizePolicy(p NormalizePolicy) {
+	n := &normalizer{policy: p, aliases: make(map[string]string, len(p.Aliases))}
+	for from, to := range p.Aliases {
+		n.aliases[n.fold(from)] = n.fold(to)
+	}
+	fs.normalizer = n
+	fs.fs.SetNormalizeFunc(func(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+		canonical := n.normalize(name)
+		if fs.spellings != nil && canonical != name {
+			fs.spellings[name] = canonical
+		}
+		return pflag.NormalizedName(canonical)
+	})
+
+	// rekey the legacyflag state by canonical name
+	meta := make(map[string]*Metadata, len(fs.meta))
+	for name, m := range fs.meta {
+		meta[fs.canonical(name)] = m
+	}
+	fs.meta = meta
+	values := make(map[string]interface{}, len(fs.values))
+	for name, v := range fs.values {
+		values[fs.canonical(name)] = v
+	}
+	fs.values = values
+	for _, a := range fs.aliases {
+		a.old, a.new = fs.canonical(a.old), fs.canonical(a.new)
+	}
+	for _, group := range fs.exclusive {
+		for i, name := range group {
+			group[i] = fs.canonical(name)
+		}
+	}
+}
+
+// canonical returns the canonical spelling of a flag name, as normalized by
+// the underlying pflag.FlagSet.
+func (fs *FlagSet) canonical(name string) string {
+	return string(fs.fs.GetNormalizeFunc()(fs.fs, name))
+}
+
+// globalName returns the name a global flag is imported as. Without a
+// normalization policy, underscores are replaced with hyphens.
+func (fs *FlagSet) globalName(name string) string {
+	if fs.normalizer == nil {
+		return normalize(name)
+	}
+	return fs.canonical(name)
+}
+
+// parseNormalized calls parse, and warns about the flags that were set using
+// a spelling other than their canonical name, if the normalization policy
+// asks for warnings.
+func (fs *FlagSet) parseNormalized(parse func() error) error {
+	if fs.normalizer == nil || fs.normalizer.policy.Warnings == nil {
+		return parse()
+	}
+	fs.spellings = map[string]string{}
+	err := parse()
+	spellings := fs.spellings
+	fs.spellings = nil
+
+	names := make([]string, 0, len(spellings))
+	for name := range spellings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// unknown flags are reported by parse
+		if f := fs.fs.Lookup(spellings[name]); f != nil {
+			fmt.Fprintf(fs.normalizer.policy.Warnings, "Flag --%s should be spelled --%s\n", name, f.Name)
+		}
+	}
+	return err
+}
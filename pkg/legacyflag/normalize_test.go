@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNormalizePolicy(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy NormalizePolicy
+		args   []string
+
+		// expect
+		set      string
+		warnings string
+		err      string
+	}{
+		{
+			name:   "canonical spelling",
+			policy: NormalizePolicy{UnderscoreHyphen: true, FoldCase: true},
+			args:   []string{"--log-dir=foo"},
+			set:    "foo",
+		},
+		{
+			name:     "underscores and case",
+			policy:   NormalizePolicy{UnderscoreHyphen: true, FoldCase: true},
+			args:     []string{"--LOG_DIR=foo"},
+			set:      "foo",
+			warnings: "Flag --LOG_DIR should be spelled --log-dir\n",
+		},
+		{
+			name:   "underscores without the underscore rule",
+			policy: NormalizePolicy{FoldCase: true},
+			args:   []string{"--log_dir=foo"},
+			err:    "unknown flag: --log_dir",
+		},
+		{
+			name: "legacy alias",
+			policy: NormalizePolicy{
+				UnderscoreHyphen: true,
+				Aliases:          map[string]string{"logs_directory": "log-dir"},
+			},
+			args:     []string{"--logs-directory=foo", "--log_dir=bar", "--logs-directory=baz"},
+			set:      "baz",
+			warnings: "Flag --log_dir should be spelled --log-dir\nFlag --logs-directory should be spelled --log-dir\n",
+		},
+		{
+			name:   "unknown flag is not a spelling",
+			policy: NormalizePolicy{UnderscoreHyphen: true},
+			args:   []string{"--no_such_flag=foo"},
+			err:    "unknown flag: --no_such_flag",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			warnings := &bytes.Buffer{}
+			c.policy.Warnings = warnings
+			fs := NewFlagSet("")
+			fs.SetNormalizePolicy(c.policy)
+			v := fs.StringVar("log-dir", "", "")
+
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("expected error %q but got %v", c.err, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			target := ""
+			v.Set(&target)
+			if target != c.set {
+				t.Errorf("Set: got %q but expected %q", target, c.set)
+			}
+			if warnings.String() != c.warnings {
+				t.Errorf("warnings: got %q but expected %q", warnings.String(), c.warnings)
+			}
+		})
+	}
+}
+
+func TestNormalizePolicyRenamesRegisteredFlags(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.StringVar("Mode_A", "", "")
+	fs.BoolVar("Mode_B", false, "")
+	if err := fs.MarkEnum("Mode_A", "x", "y"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.MarkMutuallyExclusive("Mode_A", "Mode_B"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fs.SetNormalizePolicy(NormalizePolicy{UnderscoreHyphen: true, FoldCase: true})
+
+	if f := fs.PflagFlagSet().Lookup("MODE_A"); f == nil || f.Name != "mode-a" {
+		t.Fatalf("expected --MODE_A to look up --mode-a, got %v", f)
+	}
+	if m := fs.Metadata("Mode-A"); len(m.Enum) != 2 {
+		t.Errorf("expected metadata to follow the rename, got %+v", m)
+	}
+	if err := fs.Parse([]string{"--mode_a=x"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	fs = NewFlagSet("")
+	fs.StringVar("Mode_A", "", "")
+	fs.BoolVar("Mode_B", false, "")
+	if err := fs.MarkMutuallyExclusive("Mode_A", "Mode_B"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fs.SetNormalizePolicy(NormalizePolicy{UnderscoreHyphen: true, FoldCase: true})
+	err := fs.Parse([]string{"--mode-a=x", "--mode-b"})
+	if expect := "flags --mode-a, --mode-b are mutually exclusive, but were set together"; err == nil || err.Error() != expect {
+		t.Errorf("expected error %q but got %v", expect, err)
+	}
+}
+
+func TestNormalizePolicyGlobalFlags(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.SetNormalizePolicy(NormalizePolicy{FoldCase: true})
+	fs.MustAddDeprecatedGlobalFlag("global_go_a")
+	if err := fs.AddGlobalFlags(GlobalFlagOptions{
+		Include: []string{"global_go_b"},
+		Rename:  map[string]string{"global_go_b": "Renamed_B"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f := fs.PflagFlagSet().Lookup("global_go_a"); f == nil || f.Deprecated == "" {
+		t.Errorf("expected deprecated flag --global_go_a, got %v", f)
+	}
+	if f := fs.PflagFlagSet().Lookup("renamed_b"); f == nil || f.Name != "renamed_b" {
+		t.Errorf("expected flag --renamed_b, got %v", f)
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// FieldChange is a change of the value of a config field.
+type FieldChange struct {
+	// Field is the path of the field, e.g. "Authentication.Anonymous.Enabled".
+	Field string
+	// Old and New are the values of the field before and after the change.
+	// They are Redacted if the field is set by a sensitive flag.
+	Old, New interface{}
+	// Flag is the name of the flag that set the field. Empty if the field
+	// was not set by a flag.
+	Flag string
+}
+
+// String returns the field and its values, e.g. "Port: 80 -> 8080 (--port)".
+func (c FieldChange) String() string {
+	s := fmt.Sprintf("%s: %v -> %v", c.Field, c.Old, c.New)
+	if c.Flag != "" {
+		s += " (--" + c.Flag + ")"
+	}
+	return s
+}
+
+// Reloader re-applies the flags to a config that was reloaded, e.g. when the
+// config file changed, so that the command line keeps overriding the config
+// file. See FlagSet.NewReloader.
+type Reloader struct {
+	fs    *FlagSet
+	apply func(cfg interface{}) error
+	// flags is the flag state when the Reloader was created. It is never
+	// restored, only compared to the current state, see Reload.
+	flags *Snapshot
+
+	mu sync.Mutex
+	// effective is a copy of the current effective config.
+	effective reflect.Value
+}
+
+// NewReloader returns a Reloader for the current flag state and the effective
+// config cfg, a pointer to a ComponentConfig struct that the flags were
+// already applied to. The Reloader records the flag state, but doesn't keep
+// a copy of the flag values: apply reads the current values, so if the
+// FlagSet is reparsed, restored or reset afterwards so that its flags
+// change, Reload returns an error rather than applying different flags.
+//
+// apply applies the flags to a config, typically by calling the Set, Merge
+// and Apply methods of the flag value references.
+func (fs *FlagSet) NewReloader(cfg interface{}, apply func(cfg interface{}) error) (*Reloader, error) {
+	root, err := configRoot(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if apply == nil {
+		return nil, errors.New("apply must not be nil")
+	}
+	return &Reloader{
+		fs:        fs,
+		apply:     apply,
+		flags:     fs.Snapshot(),
+		effective: copyConfig(root),
+	}, nil
+}
+
+// Reload applies the flags to cfg, a freshly decoded config of the same type
+// as the effective config, which becomes the new effective config. It returns
+// the fields of cfg that changed compared to the previous effective config,
+// with the flag that set the field, if any, and with the values of fields
+// set by sensitive flags redacted. If applying the
+// flags fails, the effective config is not changed.
+//
+// Reload doesn't modify the FlagSet, and holds it read locked while applying
+// the flags, so it can run concurrently with Parse.
+func (r *Reloader) Reload(cfg interface{}) ([]FieldChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	root, err := configRoot(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if root.Type() != r.effective.Type() {
+		return nil, fmt.Errorf("config must be a *%s, got %T", r.effective.Type(), cfg)
+	}
+
+	if err := r.applyFlags(cfg); err != nil {
+		return nil, err
+	}
+
+	changes := configChanges(r.effective, root, "")
+	for i := range changes {
+		changes[i].Flag = r.setBy(changes[i].Field)
+		if r.fs.sensitiveField(changes[i].Field) {
+			changes[i].Old, changes[i].New = Redacted, Redacted
+		}
+	}
+	r.effective = copyConfig(root)
+	return changes, nil
+}
+
+// applyFlags applies the flags to cfg if they are still in the state the
+// Reloader was created with.
+func (r *Reloader) applyFlags(cfg interface{}) error {
+	r.fs.mu.RLock()
+	defer r.fs.mu.RUnlock()
+	if !r.flags.matches(r.fs) {
+		return errors.New("flags changed since the Reloader was created")
+	}
+	if err := r.apply(cfg); err != nil {
+		return fmt.Errorf("failed to apply flags: %v", err)
+	}
+	return nil
+}
+
+// setBy returns the name of the flag that sets the config field, or a field
+// it contains, if it was set when the Reloader was created, or "" if there is
+// none.
+func (r *Reloader) setBy(field string) string {
+	for _, name := range r.fs.configFlags() {
+		cf := r.fs.meta[name].ConfigField
+		if r.flags.flags[r.fs.fs.Lookup(name)].changed && (cf == field || strings.HasPrefix(cf, field+".")) {
+			return name
+		}
+	}
+	return ""
+}
+
+// configRoot returns the struct cfg points to.
+func configRoot(cfg interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("config must be a non-nil pointer to a struct, got %T", cfg)
+	}
+	return v.Elem(), nil
+}
+
+// copyConfig returns a deep copy of the config value v. Exported fields are
+// copied deeply, unexported fields are copied as is, so they may share
+// memory with v.
+func copyConfig(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(copyConfig(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copyConfig(v.Elem()))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				c.Field(i).Set(copyConfig(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyConfig(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			c.SetMapIndex(k, copyConfig(v.MapIndex(k)))
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// configChanges returns the exported fields that differ between the config
+// values old and new, in field order. Structs, and pointers to structs that
+// are set in both, are compared field by field; other values, e.g. maps and
+// slices, are compared as a whole. path is the path of old and new.
+func configChanges(old, new reflect.Value, path string) []FieldChange {
+	if old.Kind() == reflect.Ptr && old.Type().Elem().Kind() == reflect.Struct && !old.IsNil() && !new.IsNil() {
+		return configChanges(old.Elem(), new.Elem(), path)
+	}
+	if old.Kind() != reflect.Struct {
+		if reflect.DeepEqual(old.Interface(), new.Interface()) {
+			return nil
+		}
+		return []FieldChange{{Field: path, Old: old.Interface(), New: new.Interface()}}
+	}
+	changes := []FieldChange{}
+	for i := 0; i < old.NumField(); i++ {
+		f := old.Type().Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if path != "" {
+			name = path + "." + f.Name
+		}
+		changes = append(changes, configChanges(old.Field(i), new.Field(i), name)...)
+	}
+	return changes
+}
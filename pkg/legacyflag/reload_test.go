@@ -0,0 +1,228 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// loadConfig decodes an in-memory config file.
+func loadConfig(t *testing.T, data string) *testConfig {
+	cfg := &testConfig{}
+	if err := json.Unmarshal([]byte(data), cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return cfg
+}
+
+func TestReloader(t *testing.T) {
+	fs := NewFlagSet("")
+	name := fs.StringVar("name", "", "")
+	port := fs.Int32Var("port", 0, "")
+	labels := fs.MapStringStringVar("labels", nil, "", &MapOptions{})
+	anonymous := fs.BoolVar("anonymous-auth", false, "")
+	for flag, field := range map[string]string{
+		"name":           "Name",
+		"port":           "Port",
+		"labels":         "Labels",
+		"anonymous-auth": "Auth.Anonymous.Enabled",
+	} {
+		if err := fs.MarkConfigField(flag, field); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := fs.Parse([]string{"--port=8080", "--labels=a=flag"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	apply := func(cfg interface{}) error {
+		c := cfg.(*testConfig)
+		name.Set(&c.Name)
+		port.Set(&c.Port)
+		labels.Set(&c.Labels)
+		anonymous.Apply(func(enabled bool) {
+			c.Auth = &testAuthConfig{}
+			c.Auth.Anonymous.Enabled = enabled
+		})
+		return nil
+	}
+	cfg := loadConfig(t, `{"Name": "file", "Port": 80}`)
+	if err := apply(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r, err := fs.NewReloader(cfg, apply)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		data    string
+		expect  *testConfig
+		changes []FieldChange
+	}{
+		{
+			name:    "unchanged config file",
+			data:    `{"Name": "file", "Port": 80}`,
+			expect:  &testConfig{Name: "file", Port: 8080, Labels: map[string]string{"a": "flag"}},
+			changes: []FieldChange{},
+		},
+		{
+			name:   "field without flag changed",
+			data:   `{"Name": "reloaded", "Port": 80, "Auth": {"Anonymous": {"Enabled": true}}}`,
+			expect: &testConfig{Name: "reloaded", Port: 8080, Labels: map[string]string{"a": "flag"}, Auth: &testAuthConfig{Anonymous: struct{ Enabled bool }{Enabled: true}}},
+			changes: []FieldChange{
+				{Field: "Name", Old: "file", New: "reloaded"},
+				{Field: "Auth", Old: (*testAuthConfig)(nil), New: &testAuthConfig{Anonymous: struct{ Enabled bool }{Enabled: true}}},
+			},
+		},
+		{
+			name:   "field with flag changed",
+			data:   `{"Name": "reloaded", "Port": 443, "Labels": {"b": "file"}, "Auth": {}}`,
+			expect: &testConfig{Name: "reloaded", Port: 8080, Labels: map[string]string{"a": "flag"}, Auth: &testAuthConfig{}},
+			changes: []FieldChange{
+				{Field: "Auth.Anonymous.Enabled", Old: true, New: false},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := loadConfig(t, c.data)
+			changes, err := r.Reload(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cfg, c.expect) {
+				t.Errorf("got config %#v but expected %#v", cfg, c.expect)
+			}
+			if !reflect.DeepEqual(changes, c.changes) {
+				t.Errorf("got changes %#v but expected %#v", changes, c.changes)
+			}
+		})
+	}
+
+	// Reload doesn't change the flag state
+	if !fs.PflagFlagSet().Changed("port") || !fs.PflagFlagSet().Changed("labels") || fs.PflagFlagSet().Changed("name") {
+		t.Errorf("Reload changed the flag state")
+	}
+
+	// the Reloader doesn't apply flags that changed since it was created
+	if err := fs.Reset(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Parse([]string{"--port=9090"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg = loadConfig(t, `{"Name": "file", "Port": 80}`)
+	if _, err := r.Reload(cfg); err == nil || err.Error() != "flags changed since the Reloader was created" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if cfg.Port != 80 {
+		t.Errorf("Reload applied changed flags, got port %d", cfg.Port)
+	}
+}
+
+func TestReloaderFlagOfChange(t *testing.T) {
+	fs := NewFlagSet("")
+	labels := fs.MapStringStringVar("labels", nil, "", &MapOptions{})
+	fs.StringVar("name", "", "")
+	for name, field := range map[string]string{"labels": "Labels", "name": "Name"} {
+		if err := fs.MarkConfigField(name, field); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := fs.Parse([]string{"--labels=a=flag"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	apply := func(cfg interface{}) error {
+		labels.Merge(&cfg.(*testConfig).Labels)
+		return nil
+	}
+	cfg := &testConfig{}
+	if err := apply(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r, err := fs.NewReloader(cfg, apply)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changes, err := r.Reload(loadConfig(t, `{"Name": "file", "Labels": {"b": "file"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect := []FieldChange{
+		{Field: "Name", Old: "", New: "file"},
+		{Field: "Labels", Old: map[string]string{"a": "flag"}, New: map[string]string{"a": "flag", "b": "file"}, Flag: "labels"},
+	}
+	if !reflect.DeepEqual(changes, expect) {
+		t.Errorf("got changes %#v but expected %#v", changes, expect)
+	}
+}
+
+func TestReloaderErrors(t *testing.T) {
+	fs := NewFlagSet("")
+	if _, err := fs.NewReloader(testConfig{}, func(interface{}) error { return nil }); err == nil {
+		t.Errorf("expected error for non-pointer config")
+	}
+	if _, err := fs.NewReloader(&testConfig{}, nil); err == nil || err.Error() != "apply must not be nil" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	fail := false
+	r, err := fs.NewReloader(&testConfig{Name: "a"}, func(cfg interface{}) error {
+		if fail {
+			return errors.New("apply failed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := r.Reload(&testAuthConfig{}); err == nil || err.Error() != "config must be a *legacyflag.testConfig, got *legacyflag.testAuthConfig" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	fail = true
+	if _, err := r.Reload(&testConfig{Name: "b"}); err == nil || err.Error() != "failed to apply flags: apply failed" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// the failed reload didn't change the effective config
+	fail = false
+	changes, err := r.Reload(&testConfig{Name: "a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("unexpected changes: %#v", changes)
+	}
+}
+
+func TestCopyConfig(t *testing.T) {
+	cfg := &testConfig{Labels: map[string]string{"a": "b"}, List: listValue{"a"}, Custom: testValue{s: "a"}, Auth: &testAuthConfig{}}
+	c := copyConfig(reflect.ValueOf(cfg)).Interface().(*testConfig)
+	if !reflect.DeepEqual(c, cfg) {
+		t.Fatalf("got %#v but expected %#v", c, cfg)
+	}
+	c.Labels["a"] = "c"
+	c.List[0] = "c"
+	c.Auth.Anonymous.Enabled = true
+	if cfg.Labels["a"] != "b" || cfg.List[0] != "a" || cfg.Auth.Anonymous.Enabled {
+		t.Errorf("modifying the copy modified the config: %#v", cfg)
+	}
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Schema describes every flag registered with a FlagSet, so that external
+// tools can validate command lines offline against exactly what the binary
+// accepts.
+type Schema struct {
+	Flags []FlagSchema `json:"flags"`
+	// MutuallyExclusive lists groups of flags of which at most one may be set.
+	MutuallyExclusive [][]string `json:"mutuallyExclusive,omitempty"`
+}
+
+// FlagSchema describes a single flag.
+type FlagSchema struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	// Type is the pflag type name, e.g. "stringSlice".
+	Type string `json:"type"`
+	// GoType is the Go type of the flag value, e.g. "[]string". Empty if
+	// unknown, e.g. for imported global flags.
+	GoType              string `json:"goType,omitempty"`
+	Default             string `json:"default"`
+	Usage               string `json:"usage,omitempty"`
+	Deprecated          string `json:"deprecated,omitempty"`
+	ShorthandDeprecated string `json:"shorthandDeprecated,omitempty"`
+	Hidden              bool   `json:"hidden,omitempty"`
+	ConfigField         string `json:"configField,omitempty"`
+	Section             string `json:"section,omitempty"`
+	// Visibility is omitted for public flags.
+	Visibility Visibility `json:"visibility,omitempty"`
+	Sensitive  bool       `json:"sensitive,omitempty"`
+
+	// Validation constraints.
+	Enum []string `json:"enum,omitempty"`
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+
+	// MapOptions are the parsing options of map flags. Nil for other flags.
+	MapOptions *MapOptions `json:"mapOptions,omitempty"`
+}
+
+// Schema returns a JSON document describing every flag registered with the
+// FlagSet. The document can be decoded into a Schema.
+func (fs *FlagSet) Schema() ([]byte, error) {
+	s := Schema{
+		Flags:             []FlagSchema{},
+		MutuallyExclusive: fs.exclusive,
+	}
+	fs.fs.VisitAll(func(f *pflag.Flag) {
+		m := fs.Metadata(f.Name)
+		s.Flags = append(s.Flags, FlagSchema{
+			Name:                f.Name,
+			Shorthand:           f.Shorthand,
+			Type:                f.Value.Type(),
+			GoType:              goType(fs.values[f.Name], f.Value),
+			Default:             f.DefValue,
+			Usage:               f.Usage,
+			Deprecated:          f.Deprecated,
+			ShorthandDeprecated: f.ShorthandDeprecated,
+			Hidden:              f.Hidden,
+			ConfigField:         m.ConfigField,
+			Section:             m.Section,
+			Visibility:          m.Visibility,
+			Sensitive:           m.Sensitive,
+			Enum:                m.Enum,
+			Min:                 m.Min,
+			Max:                 m.Max,
+			MapOptions:          mapOptions(f.Value),
+		})
+	})
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// NewFromSchema constructs a FlagSet that accepts the flags described by s,
+// and parses their values with the same parsers as the FlagSet that exported
+// s. Flags with types that legacyflag does not know how to parse accept any
+// value. The returned FlagSet is intended for validating command lines: it
+// does not provide value references, so flag values cannot be applied. An
+// error is returned if s is malformed, e.g. defines a flag more than once.
+func NewFromSchema(s *Schema) (*FlagSet, error) {
+	if err := validateSchema(s); err != nil {
+		return nil, err
+	}
+	fs := NewFlagSet("")
+	for i := range s.Flags {
+		sf := &s.Flags[i]
+		f := fs.fs.VarPF(newSchemaValue(sf), sf.Name, sf.Shorthand, sf.Usage)
+		f.DefValue = sf.Default
+		f.Deprecated = sf.Deprecated
+		f.ShorthandDeprecated = sf.ShorthandDeprecated
+		f.Hidden = sf.Hidden
+		if sf.Type == "bool" {
+			f.NoOptDefVal = "true"
+		}
+		m, err := fs.metadata(sf.Name)
+		if err != nil {
+			return nil, err
+		}
+		m.ConfigField = sf.ConfigField
+		m.Section = sf.Section
+		m.Visibility = sf.Visibility
+		m.Sensitive = sf.Sensitive
+		m.Enum = append([]string(nil), sf.Enum...)
+		m.Min = sf.Min
+		m.Max = sf.Max
+		if sf.Section != "" {
+			fs.addSection(sf.Section)
+		}
+	}
+	for _, group := range s.MutuallyExclusive {
+		if err := fs.MarkMutuallyExclusive(group...); err != nil {
+			return nil, err
+		}
+	}
+	return fs, nil
+}
+
+// validateSchema checks that the flag names and shorthands in s are valid and
+// unique, since pflag panics when registering such flags, and that the
+// validation constraints apply to the flag types, see MarkEnum and MarkRange.
+func validateSchema(s *Schema) error {
+	names := map[string]bool{}
+	shorthands := map[string]string{}
+	for _, sf := range s.Flags {
+		if sf.Name == "" || strings.HasPrefix(sf.Name, "-") {
+			return fmt.Errorf("invalid flag name %q", sf.Name)
+		}
+		if names[sf.Name] {
+			return fmt.Errorf("flag %q is defined more than once", sf.Name)
+		}
+		names[sf.Name] = true
+		if len(sf.Enum) > 0 && !isScalar(sf.Type) {
+			return fmt.Errorf("flag %q: enum is not supported for type %s", sf.Name, sf.Type)
+		}
+		if (sf.Min != nil || sf.Max != nil) && !isNumeric(sf.Type) {
+			return fmt.Errorf("flag %q: range is not supported for type %s", sf.Name, sf.Type)
+		}
+		if sf.Shorthand == "" {
+			continue
+		}
+		if len(sf.Shorthand) > 1 {
+			return fmt.Errorf("flag %q: shorthand %q is more than one ASCII character", sf.Name, sf.Shorthand)
+		}
+		if other, ok := shorthands[sf.Shorthand]; ok {
+			return fmt.Errorf("flag %q: shorthand %q is already used by flag %q", sf.Name, sf.Shorthand, other)
+		}
+		shorthands[sf.Shorthand] = sf.Name
+	}
+	return nil
+}
+
+// newSchemaValue returns a new pflag.Value that parses values of the type
+// described by sf.
+func newSchemaValue(sf *FlagSchema) pflag.Value {
+	switch sf.Type {
+	case "mapStringString":
+		return newMapStringString(new(map[string]string), schemaMapOptions(sf))
+	case "mapStringBool":
+		return newMapStringBool(new(map[string]bool), schemaMapOptions(sf))
+	}
+	// borrow the pflag parsers from a scratch FlagSet
+	s := pflag.NewFlagSet("", pflag.ContinueOnError)
+	switch sf.Type {
+	case "bool":
+		s.Bool(sf.Name, false, "")
+	case "boolSlice":
+		s.BoolSlice(sf.Name, nil, "")
+	case "duration":
+		s.Duration(sf.Name, 0, "")
+	case "float32":
+		s.Float32(sf.Name, 0, "")
+	case "float64":
+		s.Float64(sf.Name, 0, "")
+	case "int":
+		s.Int(sf.Name, 0, "")
+	case "int8":
+		s.Int8(sf.Name, 0, "")
+	case "int16":
+		s.Int16(sf.Name, 0, "")
+	case "int32":
+		s.Int32(sf.Name, 0, "")
+	case "int64":
+		s.Int64(sf.Name, 0, "")
+	case "intSlice":
+		s.IntSlice(sf.Name, nil, "")
+	case "ip":
+		s.IP(sf.Name, nil, "")
+	case "ipNet":
+		s.IPNet(sf.Name, net.IPNet{}, "")
+	case "string":
+		s.String(sf.Name, "", "")
+	case "stringSlice":
+		s.StringSlice(sf.Name, nil, "")
+	case "uint":
+		s.Uint(sf.Name, 0, "")
+	case "uint8":
+		s.Uint8(sf.Name, 0, "")
+	case "uint16":
+		s.Uint16(sf.Name, 0, "")
+	case "uint32":
+		s.Uint32(sf.Name, 0, "")
+	case "uint64":
+		s.Uint64(sf.Name, 0, "")
+	case "uintSlice":
+		s.UintSlice(sf.Name, nil, "")
+	default:
+		return &anyValue{typ: sf.Type}
+	}
+	return s.Lookup(sf.Name).Value
+}
+
+// schemaMapOptions returns a copy of the map options in sf, or empty options
+// if there are none.
+func schemaMapOptions(sf *FlagSchema) *MapOptions {
+	o := &MapOptions{}
+	if sf.MapOptions != nil {
+		*o = *sf.MapOptions
+	}
+	return o
+}
+
+// anyValue implements pflag.Value for flags of unknown type, and accepts any
+// value.
+type anyValue struct {
+	value string
+	typ   string
+}
+
+// String implements github.com/spf13/pflag.Value
+func (v *anyValue) String() string {
+	return v.value
+}
+
+// Set implements github.com/spf13/pflag.Value
+func (v *anyValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+// Type implements github.com/spf13/pflag.Value
+func (v *anyValue) Type() string {
+	return v.typ
+}
+
+// goTyper is implemented by the value references of the legacyflag types.
+type goTyper interface {
+	// goType returns the Go type of the flag value, e.g. "[]string".
+	goType() string
+}
+
+// goType returns the Go type of the flag value referenced by v. For flags
+// registered with Var, this is the type of the pflag.Value.
+func goType(v interface{}, value pflag.Value) string {
+	switch v := v.(type) {
+	case goTyper:
+		return v.goType()
+	case *VarValue:
+		return reflect.TypeOf(value).String()
+	}
+	return ""
+}
+
+// mapOptions returns a copy of the parsing options of map flag values, or nil
+// if value is not a map flag value.
+func mapOptions(value pflag.Value) *MapOptions {
+	var o MapOptions
+	switch v := value.(type) {
+	case *mapStringString:
+		o = *v.options
+	case *mapStringBool:
+		o = *v.options
+	default:
+		return nil
+	}
+	return &o
+}
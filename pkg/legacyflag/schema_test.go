@@ -0,0 +1,218 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"testing"
+)
+
+type testValue struct{ s string }
+
+func (v *testValue) String() string     { return v.s }
+func (v *testValue) Set(s string) error { v.s = s; return nil }
+func (v *testValue) Type() string       { return "test" }
+
+func TestSchema(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.Section("Networking").IntVar("port", 80, "The port.")
+	fs.StringSliceVar("names", []string{"a"}, "")
+	fs.IPNetVar("cidr", net.IPNet{}, "")
+	fs.MapStringStringVar("labels", nil, "", &MapOptions{KeyValueSep: ":"})
+	fs.Var(&testValue{}, "custom", "")
+	fs.BoolVar("old", false, "")
+	for _, err := range []error{
+		fs.MarkConfigField("port", "Port"),
+		fs.MarkRange("port", 1, 65535),
+		fs.MarkDeprecated("old", "Gone."),
+		fs.MarkSensitive("custom"),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	b, err := fs.Schema()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := Schema{}
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	min, max := 1.0, 65535.0
+	expect := Schema{Flags: []FlagSchema{
+		{Name: "cidr", Type: "ipNet", GoType: "net.IPNet", Default: "<nil>"},
+		{Name: "custom", Type: "test", GoType: "*legacyflag.testValue", Sensitive: true},
+		{Name: "labels", Type: "mapStringString", GoType: "map[string]string",
+			MapOptions: &MapOptions{KeyValueSep: ":", PairSep: ","}},
+		{Name: "names", Type: "stringSlice", GoType: "[]string", Default: "[a]"},
+		{Name: "old", Type: "bool", GoType: "bool", Default: "false", Deprecated: "Gone.", Hidden: true},
+		{Name: "port", Type: "int", GoType: "int", Default: "80", Usage: "The port.",
+			ConfigField: "Port", Section: "Networking", Min: &min, Max: &max},
+	}}
+	if !reflect.DeepEqual(s, expect) {
+		t.Errorf("got:\n%s\nexpected:\n%#v", b, expect)
+	}
+}
+
+func TestNewFromSchema(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.Section("Networking").IntVar("port", 80, "The port.")
+	fs.BoolVar("debug", false, "")
+	fs.StringVar("mode", "a", "")
+	fs.MapStringBoolVar("gates", nil, "", &MapOptions{PairSep: ";"})
+	fs.Var(&testValue{}, "custom", "")
+	fs.fs.Lookup("port").Shorthand = "p"
+	for _, err := range []error{
+		fs.MarkConfigField("port", "Port"),
+		fs.MarkRange("port", 1, 65535),
+		fs.MarkEnum("mode", "a", "b"),
+		fs.MarkMutuallyExclusive("debug", "mode"),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	b, err := fs.Schema()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := &Schema{}
+	if err := json.Unmarshal(b, s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fromSchema, err := NewFromSchema(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the schema of the reconstructed FlagSet only lacks Go types
+	rb, err := fromSchema.Schema()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rs := &Schema{}
+	if err := json.Unmarshal(rb, rs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range s.Flags {
+		s.Flags[i].GoType = ""
+	}
+	if !reflect.DeepEqual(rs, s) {
+		t.Errorf("got:\n%s\nexpected:\n%s", rb, b)
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{
+			name: "valid",
+			args: []string{"-p", "443", "--debug", "--gates=a=true;b=false", "--custom=anything"},
+		},
+		{
+			name: "invalid int",
+			args: []string{"--port=foo"},
+			err:  `invalid argument "foo" for "-p, --port" flag: strconv.ParseInt: parsing "foo": invalid syntax`,
+		},
+		{
+			name: "invalid map",
+			args: []string{"--gates=a=true,b=false"},
+			err:  `invalid argument "a=true,b=false" for "--gates" flag: invalid value of a: true,b=false, err: strconv.ParseBool: parsing "true,b=false": invalid syntax`,
+		},
+		{
+			name: "mutually exclusive",
+			args: []string{"--debug", "--mode=b"},
+			err:  `flags --debug, --mode are mutually exclusive, but were set together`,
+		},
+		{
+			name: "unknown flag",
+			args: []string{"--unknown"},
+			err:  `unknown flag: --unknown`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs, err := NewFromSchema(s)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			fs.fs.SetOutput(ioutil.Discard)
+			err = fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("expect error %s but got %v", c.err, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewFromSchemaErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		flags []FlagSchema
+		err   string
+	}{
+		{
+			name:  "empty name",
+			flags: []FlagSchema{{Type: "string"}},
+			err:   `invalid flag name ""`,
+		},
+		{
+			name:  "duplicate name",
+			flags: []FlagSchema{{Name: "a", Type: "string"}, {Name: "a", Type: "int"}},
+			err:   `flag "a" is defined more than once`,
+		},
+		{
+			name:  "long shorthand",
+			flags: []FlagSchema{{Name: "a", Shorthand: "ab", Type: "string"}},
+			err:   `flag "a": shorthand "ab" is more than one ASCII character`,
+		},
+		{
+			name:  "duplicate shorthand",
+			flags: []FlagSchema{{Name: "a", Shorthand: "x", Type: "string"}, {Name: "b", Shorthand: "x", Type: "string"}},
+			err:   `flag "b": shorthand "x" is already used by flag "a"`,
+		},
+		{
+			name:  "enum on slice",
+			flags: []FlagSchema{{Name: "a", Type: "stringSlice", Enum: []string{"x"}}},
+			err:   `flag "a": enum is not supported for type stringSlice`,
+		},
+		{
+			name:  "range on string",
+			flags: []FlagSchema{{Name: "a", Type: "string", Max: new(float64)}},
+			err:   `flag "a": range is not supported for type string`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewFromSchema(&Schema{Flags: c.flags})
+			if err == nil || err.Error() != c.err {
+				t.Errorf("expected error %s but got %v", c.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/spf13/pflag"
+)
+
+// Snapshot is the saved state of the flags of a FlagSet, see
+// FlagSet.Snapshot.
+type Snapshot struct {
+	state   *state
+	flags   map[*pflag.Flag]flagState
+	aliases map[*alias]alias
+	args    []string
+}
+
+// flagState is the saved state of a flag.
+type flagState struct {
+	// value is the string representation of the flag value.
+	value string
+	// typed is a copy of the value of a flag of a legacyflag type that
+	// rebind restores, or nil for other flags.
+	typed   interface{}
+	changed bool
+}
+
+// Snapshot saves the values of the flags and whether they were set, so that
+// Restore can return the FlagSet to this state, e.g. before reparsing a
+// command line. The values of the legacyflag types are copied, and other
+// values are saved as strings, see Restore.
+func (fs *FlagSet) Snapshot() *Snapshot {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	s := &Snapshot{
+		state:   fs.state,
+		flags:   map[*pflag.Flag]flagState{},
+		aliases: make(map[*alias]alias, len(fs.aliases)),
+		args:    fs.args,
+	}
+	fs.fs.VisitAll(func(f *pflag.Flag) {
+		s.flags[f] = fs.saveFlag(f)
+	})
+	for _, a := range fs.aliases {
+		s.aliases[a] = *a
+	}
+	return s
+}
+
+// Restore returns the flags to the state saved by Snapshot. Flags registered
+// after the snapshot was taken are reset, see Reset. The snapshot can be
+// restored more than once.
+//
+// The values of the legacyflag types are restored from their saved copies,
+// and keep their parse state, e.g. slices append if they were set. Other
+// values, e.g. registered with Var or TypedVar, are set to their saved string
+// with Set if it differs from their current string, which requires Set to
+// replace the value and to accept the output of String.
+func (fs *FlagSet) Restore(s *Snapshot) error {
+	if s.state != fs.state {
+		return errors.New("snapshot was taken from a different FlagSet")
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	var err error
+	fs.fs.VisitAll(func(f *pflag.Flag) {
+		saved, ok := s.flags[f]
+		if !ok {
+			saved = fs.defaultState(f)
+		}
+		if restoreErr := fs.restoreFlag(f, saved); err == nil {
+			err = restoreErr
+		}
+	})
+	for _, a := range fs.aliases {
+		saved := s.aliases[a]
+		a.resolved, a.marked = saved.resolved, saved.marked
+	}
+	fs.args = s.args
+	return err
+}
+
+// Reset returns the flags to their default values and marks them as not
+// set, as if the FlagSet had never been parsed. The default value of a flag
+// is its value when it was registered, and is restored like Restore does.
+// Flags that were added directly to the underlying pflag.FlagSet are only
+// marked as not set.
+//
+// pflag offers no way to forget that a flag was set, so Visit and NFlag of
+// the underlying pflag.FlagSet still report the flags that were set before.
+func (fs *FlagSet) Reset() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	var err error
+	fs.fs.VisitAll(func(f *pflag.Flag) {
+		if resetErr := fs.restoreFlag(f, fs.defaultState(f)); err == nil {
+			err = resetErr
+		}
+	})
+	for _, a := range fs.aliases {
+		a.resolved, a.marked = false, false
+	}
+	fs.args = nil
+	return err
+}
+
+// matches returns whether the flags of fs are in the state saved by s. Flags
+// registered after s was taken match if they are not set. The caller must
+// hold the read lock.
+func (s *Snapshot) matches(fs *FlagSet) bool {
+	match := true
+	fs.fs.VisitAll(func(f *pflag.Flag) {
+		saved, ok := s.flags[f]
+		if !ok {
+			match = match && !f.Changed
+			return
+		}
+		match = match && f.Changed == saved.changed && f.Value.String() == saved.value
+	})
+	return match
+}
+
+// saveDefault saves the current value of the named flag as its default
+// value, see Reset.
+func (fs *FlagSet) saveDefault(name string) {
+	if f := fs.fs.Lookup(name); f != nil {
+		fs.defaults[f] = fs.saveFlag(f)
+	}
+}
+
+// defaultState returns the saved default state of f, or its current value
+// if there is none. The flag is not set in either case.
+func (fs *FlagSet) defaultState(f *pflag.Flag) flagState {
+	def, ok := fs.defaults[f]
+	if !ok {
+		def = fs.saveFlag(f)
+	}
+	def.changed = false
+	return def
+}
+
+// saveFlag returns the current state of f.
+func (fs *FlagSet) saveFlag(f *pflag.Flag) flagState {
+	return flagState{value: f.Value.String(), typed: fs.typedValue(f), changed: f.Changed}
+}
+
+// typedValue returns a copy of the value of a flag of a legacyflag type that
+// rebind restores, or nil for other flags.
+func (fs *FlagSet) typedValue(f *pflag.Flag) interface{} {
+	switch v := fs.values[f.Name].(type) {
+	case *StringSliceValue:
+		return cloneSlice(v.value)
+	case *IntSliceValue:
+		return cloneSlice(v.value)
+	case *UintSliceValue:
+		return cloneSlice(v.value)
+	case *BoolSliceValue:
+		return cloneSlice(v.value)
+	case *IPValue:
+		return net.IP(cloneSlice(v.value))
+	case *IPNetValue:
+		return net.IPNet{IP: cloneSlice(v.value.IP), Mask: cloneSlice(v.value.Mask)}
+	case *MapStringStringValue:
+		return cloneMap(v.value)
+	case *MapStringBoolValue:
+		return cloneMap(v.value)
+	}
+	return nil
+}
+
+// restoreFlag sets f to the saved value, and marks it as set or not set.
+// Aliases share the value of their flag, which is restored by itself.
+func (fs *FlagSet) restoreFlag(f *pflag.Flag, saved flagState) error {
+	if !fs.isAlias(f.Name) && !fs.rebind(f, saved.typed, saved.changed) && f.Value.String() != saved.value {
+		if err := f.Value.Set(saved.value); err != nil {
+			return fmt.Errorf("failed to restore flag --%s: %v", f.Name, err)
+		}
+	}
+	f.Changed = saved.changed
+	return nil
+}
+
+// isAlias returns true if name is an alias registered by Alias.
+func (fs *FlagSet) isAlias(name string) bool {
+	for _, a := range fs.aliases {
+		if a.old == name {
+			return true
+		}
+	}
+	return false
+}
+
+// rebind sets the value of a flag of a legacyflag type that keeps parse
+// state, e.g. slices that append once they were set, or that can't parse the
+// string of its zero value, e.g. IPs, to a copy of value, which was returned
+// by typedValue. It replaces the pflag.Value of the flag with a new one for
+// the same value, as if the flag was just registered, and marks the new
+// pflag.Value as set if changed is set, so that parsing appends to slices and
+// maps rather than replacing them. rebind returns false for other flags.
+func (fs *FlagSet) rebind(f *pflag.Flag, value interface{}, changed bool) bool {
+	tmp := pflag.NewFlagSet(f.Name, pflag.ContinueOnError)
+	// setting a placeholder marks a slice as set, before the copy replaces it
+	switch v := fs.values[f.Name].(type) {
+	case *StringSliceValue:
+		s := cloneSlice(value.([]string))
+		tmp.StringSliceVar(&v.value, f.Name, s, "")
+		if changed {
+			tmp.Set(f.Name, "placeholder")
+			v.value = s
+		}
+	case *IntSliceValue:
+		s := cloneSlice(value.([]int))
+		tmp.IntSliceVar(&v.value, f.Name, s, "")
+		if changed {
+			tmp.Set(f.Name, "0")
+			v.value = s
+		}
+	case *UintSliceValue:
+		s := cloneSlice(value.([]uint))
+		tmp.UintSliceVar(&v.value, f.Name, s, "")
+		if changed {
+			tmp.Set(f.Name, "0")
+			v.value = s
+		}
+	case *BoolSliceValue:
+		s := cloneSlice(value.([]bool))
+		tmp.BoolSliceVar(&v.value, f.Name, s, "")
+		if changed {
+			tmp.Set(f.Name, "false")
+			v.value = s
+		}
+	case *IPValue:
+		tmp.IPVar(&v.value, f.Name, cloneSlice(value.(net.IP)), "")
+	case *IPNetValue:
+		n := value.(net.IPNet)
+		tmp.IPNetVar(&v.value, f.Name, net.IPNet{IP: cloneSlice(n.IP), Mask: cloneSlice(n.Mask)}, "")
+	case *MapStringStringValue:
+		v.value = cloneMap(value.(map[string]string))
+		m := newMapStringString(&v.value, mapOptions(f.Value))
+		m.initialized = changed
+		tmp.Var(m, f.Name, "")
+	case *MapStringBoolValue:
+		v.value = cloneMap(value.(map[string]bool))
+		m := newMapStringBool(&v.value, mapOptions(f.Value))
+		m.initialized = changed
+		tmp.Var(m, f.Name, "")
+	default:
+		return false
+	}
+	f.Value = tmp.Lookup(f.Name).Value
+	// aliases are copies of the flag that share its value
+	for _, a := range fs.aliases {
+		if a.new == f.Name {
+			fs.fs.Lookup(a.old).Value = f.Value
+		}
+	}
+	return true
+}
+
+// cloneSlice returns a copy of s, which is nil if s is nil.
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
+
+// cloneMap returns a copy of m, which is nil if m is nil.
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
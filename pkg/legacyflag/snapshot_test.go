@@ -0,0 +1,209 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"io/ioutil"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestReset(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.PflagFlagSet().SetOutput(ioutil.Discard)
+	name := fs.StringVar("name", "default", "")
+	slice := fs.StringSliceVar("slice", []string{"default"}, "")
+	labels := fs.MapStringStringVar("labels", map[string]string{"default": "true"}, "", &MapOptions{})
+	ip := fs.IPVar("ip", nil, "")
+	custom := &testValue{s: "default"}
+	fs.Var(custom, "custom", "")
+	if err := fs.Alias("old-name", "name", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fs.SetAliasPolicy(AliasConflictError)
+	// get returns the current values of the flags, whether or not they were
+	// set
+	get := func() map[string]interface{} {
+		return map[string]interface{}{
+			"name":   name.value,
+			"slice":  slice.value,
+			"labels": labels.value,
+			"ip":     ip.value,
+			"custom": custom.s,
+		}
+	}
+	defaults := get()
+	if err := fs.Parse([]string{"--old-name=a", "--slice=a", "--labels=a=b", "--ip=10.0.0.1", "--custom=a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := fs.Reset(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := get(); !reflect.DeepEqual(got, defaults) {
+		t.Errorf("got %#v but expected the defaults %#v", got, defaults)
+	}
+	for _, name := range []string{"name", "old-name", "slice", "labels", "ip", "custom"} {
+		if fs.PflagFlagSet().Changed(name) {
+			t.Errorf("flag --%s is set after Reset", name)
+		}
+	}
+
+	// reparsing replaces the defaults again, and the alias doesn't conflict
+	// with the previous parse
+	if err := fs.Parse([]string{"--name=b", "--slice=b", "--labels=b=c", "--ip=10.0.0.2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect := map[string]interface{}{
+		"name":   "b",
+		"slice":  []string{"b"},
+		"labels": map[string]string{"b": "c"},
+		"ip":     net.ParseIP("10.0.0.2"),
+		"custom": "default",
+	}
+	if got := get(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("got %#v but expected %#v", got, expect)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.PflagFlagSet().SetOutput(ioutil.Discard)
+	name := fs.StringVar("name", "default", "")
+	slice := fs.StringSliceVar("slice", []string{"default"}, "")
+	labels := fs.MapStringStringVar("labels", map[string]string{"default": "true"}, "", &MapOptions{})
+	ip := fs.IPVar("ip", nil, "")
+	custom := &testValue{s: "default"}
+	fs.Var(custom, "custom", "")
+	if err := fs.Alias("old-name", "name", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fs.SetAliasPolicy(AliasConflictError)
+	// get returns the current values of the flags, whether or not they were
+	// set
+	get := func() map[string]interface{} {
+		return map[string]interface{}{
+			"name":   name.value,
+			"slice":  slice.value,
+			"labels": labels.value,
+			"ip":     ip.value,
+			"custom": custom.s,
+		}
+	}
+	if err := fs.Parse([]string{"--old-name=a", "--slice=a", "--labels=a=b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := fs.Snapshot()
+	expect := map[string]interface{}{
+		"name":   "a",
+		"slice":  []string{"a"},
+		"labels": map[string]string{"a": "b"},
+		"ip":     net.IP(nil),
+		"custom": "default",
+	}
+
+	// restoring is repeatable
+	for i := 0; i < 2; i++ {
+		if err := fs.Parse([]string{"--slice=b", "--labels=b=c", "--ip=10.0.0.2", "--custom=b"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := fs.Restore(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := get(); !reflect.DeepEqual(got, expect) {
+			t.Errorf("got %#v but expected %#v", got, expect)
+		}
+		for name, changed := range map[string]bool{"name": true, "old-name": true, "slice": true, "labels": true, "ip": false, "custom": false} {
+			if fs.PflagFlagSet().Changed(name) != changed {
+				t.Errorf("flag --%s: expected Changed to be %v", name, changed)
+			}
+		}
+	}
+
+	// the restored values keep their parse state, e.g. slices append
+	if err := fs.Parse([]string{"--slice=c"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, expect := slice.value, []string{"a", "c"}; !reflect.DeepEqual(got, expect) {
+		t.Errorf("got %#v but expected %#v", got, expect)
+	}
+	// the alias was resolved before the snapshot, so setting the flag again
+	// doesn't conflict
+	if err := fs.Parse([]string{"--name=c"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRestoreFlagsRegisteredAfterSnapshot(t *testing.T) {
+	fs := NewFlagSet("")
+	s := fs.Snapshot()
+	later := fs.IntVar("later", 1, "")
+	if err := fs.Parse([]string{"--later=2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Restore(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if later.value != 1 || fs.PflagFlagSet().Changed("later") {
+		t.Errorf("expected flag --later to be reset, got %d", later.value)
+	}
+}
+
+func TestRestoreDifferentFlagSet(t *testing.T) {
+	fs := NewFlagSet("")
+	other := NewFlagSet("")
+	err := fs.Restore(other.Snapshot())
+	if expect := "snapshot was taken from a different FlagSet"; err == nil || err.Error() != expect {
+		t.Errorf("expected error %q but got %v", expect, err)
+	}
+	// sections share the state of their FlagSet
+	if err := fs.Section("Section").Restore(fs.Snapshot()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRestoreMapWithoutCommaSeparatedPairs(t *testing.T) {
+	fs := NewFlagSet("")
+	m := fs.MapStringStringVar("m", map[string]string{"default": "x,y"}, "", &MapOptions{DisableCommaSeparatedPairs: true})
+	if err := fs.Parse([]string{"--m=a=b,c", "--m=z=1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := fs.Snapshot()
+	if err := fs.Parse([]string{"--m=b=2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Restore(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expect := map[string]string{"a": "b,c", "z": "1"}; !reflect.DeepEqual(m.value, expect) {
+		t.Errorf("Restore: got %#v but expected %#v", m.value, expect)
+	}
+	// the restored map was set, so parsing adds to it
+	if err := fs.Parse([]string{"--m=y=2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expect := map[string]string{"a": "b,c", "z": "1", "y": "2"}; !reflect.DeepEqual(m.value, expect) {
+		t.Errorf("Parse: got %#v but expected %#v", m.value, expect)
+	}
+	if err := fs.Reset(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expect := map[string]string{"default": "x,y"}; !reflect.DeepEqual(m.value, expect) {
+		t.Errorf("Reset: got %#v but expected %#v", m.value, expect)
+	}
+}
@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,36 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
+import (
 	"github.com/spf13/pflag"
-	
 )
 
 // StringSliceValue is a reference to a registered []string flag value.
 type StringSliceValue struct {
-	name string
+	name  string
 	value []string
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // StringSliceVar registers a flag for []string against the FlagSet, and
 // returns a StringSliceValue reference to the registered flag value.
 func (fs *FlagSet) StringSliceVar(name string, def []string, usage string) *StringSliceValue {
+	return fs.StringSliceVarP(name, "", def, usage)
+}
+
+// StringSliceVarP is like StringSliceVar, but accepts a shorthand letter that can
+// be used after a single dash.
+func (fs *FlagSet) StringSliceVarP(name, shorthand string, def []string, usage string) *StringSliceValue {
 	v := &StringSliceValue{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.StringSliceVar(&v.value, name, def, usage)
+	fs.fs.StringSliceVarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -55,3 +61,8 @@ func (v *StringSliceValue) Apply(apply func(value []string)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *StringSliceValue) goType() string {
+	return "[]string"
+}
@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,33 +14,76 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
-	"testing"
+import (
+	"io/ioutil"
 	"reflect"
-	
+	"strings"
+	"testing"
 )
 
 func TestStringVar(t *testing.T) {
 	cases := []struct {
-		name string
-		args []string
+		name  string
+		args  []string
+		def   string
 		set   string
 		apply bool
+		err   string
 	}{
 		{
-			name: "flag is set",
-			args: []string{"--foo=foo"},
-			set: "foo",
+			name:  "flag is set",
+			args:  []string{"--foo=foo"},
+			set:   "foo",
+			apply: true,
+		},
+		{
+			name:  "flag is set with shorthand",
+			args:  []string{"-f=foo"},
+			set:   "foo",
+			apply: true,
+		},
+		{
+			name:  "flag is set with alias",
+			args:  []string{"--old-foo=foo"},
+			set:   "foo",
 			apply: true,
 		},
 		{
 			name: "flag is not set",
-			args: []string{""},
-			apply: false,
+			args: []string{},
+		},
+		{
+			name: "default is not copied when flag is not set",
+			args: []string{},
+			def:  "default",
+		},
+		{
+			name:  "empty value",
+			args:  []string{"--foo="},
+			def:   "default",
+			set:   "",
+			apply: true,
+		},
+		{
+			name:  "value is not trimmed",
+			args:  []string{"--foo= foo "},
+			set:   " foo ",
+			apply: true,
+		},
+		{
+			name:  "repeated flag, last wins",
+			args:  []string{"--foo=a", "--foo=b"},
+			set:   "b",
+			apply: true,
+		},
+		{
+			name: "missing argument",
+			args: []string{"--foo"},
+			err:  "flag needs an argument: --foo",
 		},
 	}
 
@@ -49,8 +92,19 @@ func TestStringVar(t *testing.T) {
 			var target string
 
 			fs := NewFlagSet("")
-			val := fs.StringVar("foo", target, "")
-			if err := fs.Parse(c.args); err != nil {
+			val := fs.StringVarP("foo", "f", c.def, "")
+			fs.PflagFlagSet().SetOutput(ioutil.Discard)
+			if err := fs.Alias("old-foo", "foo", ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
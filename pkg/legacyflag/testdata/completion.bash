@@ -0,0 +1,145 @@
+# bash completion for kubelet, generated by legacyflag.
+
+# __kubelet_words prefix words...: completes ${value} from words.
+__kubelet_words()
+{
+    local prefix="$1"
+    shift
+    local IFS=$'\n'
+    COMPREPLY=( $(compgen -P "${prefix}" -W "$(printf '%s\n' "$@")" -- "${value}") )
+}
+
+# __kubelet_pairs prefix sep words...: completes the last of the pairs separated
+# by sep in ${value} from words.
+__kubelet_pairs()
+{
+    local prefix="$1" sep="$2" pairs=""
+    shift 2
+    if [[ "${value}" == *"${sep}"* ]]; then
+        pairs="${value%"${sep}"*}${sep}"
+    fi
+    local value="${value#"${pairs}"}"
+    __kubelet_words "${prefix}${pairs}" "$@"
+}
+
+# __kubelet_bools prefix sep kvsep: completes true or false as the value of the
+# last of the pairs separated by sep in ${value}, once its key followed by
+# kvsep is typed.
+__kubelet_bools()
+{
+    local prefix="$1" sep="$2" kvsep="$3" pairs=""
+    if [[ -n "${sep}" && "${value}" == *"${sep}"* ]]; then
+        pairs="${value%"${sep}"*}${sep}"
+    fi
+    local pair="${value#"${pairs}"}"
+    if [[ "${pair}" != *"${kvsep}"* ]]; then
+        COMPREPLY=()
+        return
+    fi
+    local key="${pair%%"${kvsep}"*}${kvsep}"
+    local value="${pair#"${key}"}"
+    __kubelet_words "${prefix}${pairs}${key}" true false
+}
+
+# __kubelet_files prefix extensions...: completes file paths, restricted to
+# files with the given extensions, if any.
+__kubelet_files()
+{
+    local prefix="$1" f ext
+    shift
+    local IFS=$'\n'
+    COMPREPLY=()
+    for f in $(compgen -f -- "${value}"); do
+        if [[ $# -eq 0 || -d "${f}" ]]; then
+            COMPREPLY+=( "${prefix}${f}" )
+            continue
+        fi
+        for ext in "$@"; do
+            if [[ "${f}" == *."${ext}" ]]; then
+                COMPREPLY+=( "${prefix}${f}" )
+            fi
+        done
+    done
+    compopt -o filenames
+}
+
+# __kubelet_ltrim removes the part of the replies that precedes the last word
+# break character in ${cur}, since bash only replaces the text after it.
+__kubelet_ltrim()
+{
+    local i
+    for (( i=${#cur}-1; i>=0; i-- )); do
+        if [[ "${COMP_WORDBREAKS}" == *"${cur:i:1}"* ]]; then
+            COMPREPLY=( "${COMPREPLY[@]#"${cur:0:i+1}"}" )
+            return
+        fi
+    done
+}
+
+_kubelet()
+{
+    local line="${COMP_LINE:0:${COMP_POINT}}"
+    local cur="${line##*[[:space:]]}"
+    local before="${line%"${cur}"}"
+    before="${before%"${before##*[![:space:]]}"}"
+    local prev="${before##*[[:space:]]}"
+    local flag="" value="${cur}" prefix=""
+    if [[ "${cur}" == -*=* ]]; then
+        flag="${cur%%=*}"
+        value="${cur#*=}"
+        prefix="${flag}="
+    elif [[ "${cur}" != -* ]]; then
+        case "${prev}" in
+            --config)
+                flag="${prev}"
+                ;;
+            --feature-gates)
+                flag="${prev}"
+                ;;
+            --gates)
+                flag="${prev}"
+                ;;
+            --kubeconfig)
+                flag="${prev}"
+                ;;
+            --mode)
+                flag="${prev}"
+                ;;
+            --node-labels)
+                flag="${prev}"
+                ;;
+        esac
+    fi
+
+    case "${flag}" in
+        --config)
+            __kubelet_files "${prefix}" yaml json
+            ;;
+        --debug|-d)
+            __kubelet_words "${prefix}" 'true' 'false'
+            ;;
+        --feature-gates)
+            __kubelet_pairs "${prefix}" ',' 'Foo=true' 'Foo=false' 'Bar=true' 'Bar=false'
+            ;;
+        --gates)
+            __kubelet_bools "${prefix}" ';' '='
+            ;;
+        --kubeconfig)
+            __kubelet_files "${prefix}" 
+            ;;
+        --mode)
+            __kubelet_words "${prefix}" 'a' 'b'
+            ;;
+        --node-labels)
+            __kubelet_pairs "${prefix}" ',' 'zone=' 'rack='; compopt -o nospace
+            ;;
+        "")
+            if [[ "${cur}" == -* ]]; then
+                __kubelet_words "" --annotations --config --debug -d --feature-gates --gates --kubeconfig --mode --node-labels --port -p
+            fi
+            ;;
+    esac
+    __kubelet_ltrim
+}
+
+complete -o default -F _kubelet kubelet
@@ -0,0 +1,25 @@
+# fish completion for kubelet, generated by legacyflag.
+
+# __kubelet_bools sep kvsep: prints the flag value being completed with true or
+# false as the value of its last pair, once its key followed by kvsep is typed.
+function __kubelet_bools -a sep kvsep
+    set -l value (string replace -r -- '^-[^=]*=' '' (commandline -ct))
+    set -l pairs ''
+    if test -n "$sep"
+        set pairs (string match -r -- '^.*'(string escape --style=regex -- $sep) $value)
+    end
+    set -l pair (string sub -s (math (string length -- "$pairs") + 1) -- $value)
+    set -l key (string match -r -- '^.*?'(string escape --style=regex -- $kvsep) $pair)
+    test -n "$key"; or return
+    printf '%s\n' $pairs$key'true' $pairs$key'false'
+end
+
+complete -c 'kubelet' -l 'annotations' -d 'Unknown keys.' -x
+complete -c 'kubelet' -l 'config' -d 'Path to the config file.' -r -a '(__fish_complete_suffix .yaml) (__fish_complete_suffix .json)'
+complete -c 'kubelet' -l 'debug' -s 'd' -d 'Enable [debug] mode.'
+complete -c 'kubelet' -l 'feature-gates' -d 'Feature gates.' -x -a 'Foo=true Foo=false Bar=true Bar=false'
+complete -c 'kubelet' -l 'gates' -d 'Unknown gates.' -x -a '(__kubelet_bools \';\' \'=\')'
+complete -c 'kubelet' -l 'kubeconfig' -d 'Path to a kubeconfig file.' -r
+complete -c 'kubelet' -l 'mode' -d 'The mode: a or b.' -x -a 'a b'
+complete -c 'kubelet' -l 'node-labels' -d 'Node labels.' -x -a 'zone= rack='
+complete -c 'kubelet' -l 'port' -s 'p' -d 'The port to serve on.' -x
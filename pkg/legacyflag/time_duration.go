@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,38 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
-	"github.com/spf13/pflag"
+import (
 	"time"
+
+	"github.com/spf13/pflag"
 )
 
 // DurationValue is a reference to a registered time.Duration flag value.
 type DurationValue struct {
-	name string
+	name  string
 	value time.Duration
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // DurationVar registers a flag for time.Duration against the FlagSet, and returns
 // a DurationValue reference to the registered flag value.
 func (fs *FlagSet) DurationVar(name string, def time.Duration, usage string) *DurationValue {
+	return fs.DurationVarP(name, "", def, usage)
+}
+
+// DurationVarP is like DurationVar, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) DurationVarP(name, shorthand string, def time.Duration, usage string) *DurationValue {
 	v := &DurationValue{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.DurationVar(&v.value, name, def, usage)
+	fs.fs.DurationVarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -54,3 +62,8 @@ func (v *DurationValue) Apply(apply func(value time.Duration)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *DurationValue) goType() string {
+	return "time.Duration"
+}
@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,33 +14,71 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
-	"testing"
+import (
+	"io/ioutil"
 	"reflect"
+	"strings"
+	"testing"
 	"time"
 )
 
 func TestDurationVar(t *testing.T) {
 	cases := []struct {
-		name string
-		args []string
+		name  string
+		args  []string
+		def   time.Duration
 		set   time.Duration
 		apply bool
+		err   string
 	}{
 		{
-			name: "flag is set",
-			args: []string{"--foo=100ns"},
-			set: time.Duration(100),
+			name:  "flag is set",
+			args:  []string{"--foo=100ns"},
+			set:   time.Duration(100),
+			apply: true,
+		},
+		{
+			name:  "flag is set with shorthand",
+			args:  []string{"-f=100ns"},
+			set:   time.Duration(100),
+			apply: true,
+		},
+		{
+			name:  "flag is set with alias",
+			args:  []string{"--old-foo=100ns"},
+			set:   time.Duration(100),
 			apply: true,
 		},
 		{
 			name: "flag is not set",
-			args: []string{""},
-			apply: false,
+			args: []string{},
+		},
+		{
+			name: "default is not copied when flag is not set",
+			args: []string{},
+			def:  time.Second,
+		},
+		{
+			name:  "compound duration",
+			args:  []string{"--foo=1h30m"},
+			set:   90 * time.Minute,
+			apply: true,
+		},
+		{
+			name:  "zero overrides default",
+			args:  []string{"--foo=0"},
+			def:   time.Second,
+			set:   0,
+			apply: true,
+		},
+		{
+			name: "missing unit",
+			args: []string{"--foo=10"},
+			err:  "invalid argument \"10\" for \"-f, --foo\" flag",
 		},
 	}
 
@@ -49,8 +87,19 @@ func TestDurationVar(t *testing.T) {
 			var target time.Duration
 
 			fs := NewFlagSet("")
-			val := fs.DurationVar("foo", target, "")
-			if err := fs.Parse(c.args); err != nil {
+			val := fs.DurationVarP("foo", "f", c.def, "")
+			fs.PflagFlagSet().SetOutput(ioutil.Discard)
+			if err := fs.Alias("old-foo", "foo", ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := fs.Parse(c.args)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q but got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+// Getter is implemented by pflag.Value types that expose their parsed value,
+// e.g. the flag.Getter types of the Go flag package.
+type Getter interface {
+	pflag.Value
+	// Get returns the parsed value.
+	Get() interface{}
+}
+
+// TypedVarValue is a reference to a registered flag for a type that
+// implements the pflag.Value interface. Unlike VarValue, it exposes the parsed
+// value as a T, like the value references for the built-in types.
+type TypedVarValue[T any] struct {
+	name    string
+	value   pflag.Value
+	extract func(pflag.Value) T
+	merge   func(target *T, value T)
+	fs      *pflag.FlagSet
+}
+
+// TypedVar registers a flag for a type that implements the pflag.Value
+// interface against the FlagSet, and returns a TypedVarValue reference to the
+// registered flag value. extract returns the parsed value of the flag. If
+// extract is nil, the parsed value is returned by Get if value implements
+// Getter, and is otherwise the value pointed to by value, converted to T.
+// TypedVar panics if extract is nil and the parsed value can't be converted
+// to T.
+func TypedVar[T any](fs *FlagSet, value pflag.Value, name string, usage string, extract func(pflag.Value) T) *TypedVarValue[T] {
+	return TypedVarP(fs, value, name, "", usage, extract)
+}
+
+// TypedVarP is like TypedVar, but accepts a shorthand letter that can be used
+// after a single dash.
+func TypedVarP[T any](fs *FlagSet, value pflag.Value, name, shorthand string, usage string, extract func(pflag.Value) T) *TypedVarValue[T] {
+	if extract == nil {
+		extract = defaultExtract[T]
+		// fail at registration rather than on first use
+		extract(value)
+	}
+	v := &TypedVarValue[T]{
+		name:    name,
+		value:   value,
+		extract: extract,
+		merge:   defaultMerge[T],
+		fs:      fs.fs,
+	}
+	fs.fs.VarP(value, name, shorthand, usage)
+	fs.register(name, v)
+	return v
+}
+
+// defaultExtract returns the value of Get if value implements Getter, or the
+// value pointed to by value, converted to T. A nil value of Get is returned
+// as the zero value of T.
+func defaultExtract[T any](value pflag.Value) T {
+	var zero T
+	t := reflect.TypeOf(&zero).Elem()
+	var v reflect.Value
+	if g, ok := value.(Getter); ok {
+		if v = reflect.ValueOf(g.Get()); !v.IsValid() {
+			return zero
+		}
+	} else if v = reflect.ValueOf(value); v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.Type().ConvertibleTo(t) {
+		panic(fmt.Sprintf("legacyflag: flag value of type %s can't be converted to %s", v.Type(), t))
+	}
+	return v.Convert(t).Interface().(T)
+}
+
+// defaultMerge merges value into target. Maps are merged key by key, and
+// slices are appended. Other values overwrite the target, as Set does. A nil
+// map is not merged.
+func defaultMerge[T any](target *T, value T) {
+	t := reflect.ValueOf(target).Elem()
+	v := reflect.ValueOf(&value).Elem()
+	switch t.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		if t.IsNil() {
+			t.Set(reflect.MakeMap(t.Type()))
+		}
+		for _, k := range v.MapKeys() {
+			t.SetMapIndex(k, v.MapIndex(k))
+		}
+	case reflect.Slice:
+		t.Set(reflect.AppendSlice(t, v))
+	default:
+		*target = value
+	}
+}
+
+// MergeFunc sets the func that Merge uses to merge the flag value into a
+// target, e.g. for composite types that need custom merge semantics. merge is
+// called with the target passed to Merge and the parsed value.
+func (v *TypedVarValue[T]) MergeFunc(merge func(target *T, value T)) *TypedVarValue[T] {
+	v.merge = merge
+	return v
+}
+
+// Get returns the parsed value of the flag, whether or not it was set.
+func (v *TypedVarValue[T]) Get() T {
+	return v.extract(v.value)
+}
+
+// goType implements goTyper.
+func (v *TypedVarValue[T]) goType() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
+
+// Set copies the flag value to the target if the flag was set.
+func (v *TypedVarValue[T]) Set(target *T) {
+	if v.fs.Changed(v.name) {
+		*target = v.Get()
+	}
+}
+
+// Merge merges the flag value into the target if the flag was set. By
+// default, maps are merged key by key, slices are appended, and other values
+// are copied as Set does. See MergeFunc.
+func (v *TypedVarValue[T]) Merge(target *T) {
+	if v.fs.Changed(v.name) {
+		v.merge(target, v.Get())
+	}
+}
+
+// Apply calls the apply func with the flag value if the flag was set.
+func (v *TypedVarValue[T]) Apply(apply func(value T)) {
+	if v.fs.Changed(v.name) {
+		apply(v.Get())
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// listValue is a pflag.Value for a list, without a Get method.
+type listValue []string
+
+func (l *listValue) String() string     { return strings.Join(*l, ",") }
+func (l *listValue) Set(s string) error { *l = append(*l, strings.Split(s, ",")...); return nil }
+func (l *listValue) Type() string       { return "list" }
+
+// labelsValue is a Getter for a map.
+type labelsValue struct{ m map[string]string }
+
+func (l *labelsValue) String() string { return "" }
+func (l *labelsValue) Set(s string) error {
+	kv := strings.SplitN(s, "=", 2)
+	if l.m == nil {
+		l.m = map[string]string{}
+	}
+	l.m[kv[0]] = kv[1]
+	return nil
+}
+func (l *labelsValue) Type() string     { return "labels" }
+func (l *labelsValue) Get() interface{} { return l.m }
+
+func TestTypedVar(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+
+		// expect
+		set   []string
+		merge []string
+		apply bool
+	}{
+		{
+			name:  "flag is set",
+			args:  []string{"--list=a,b", "-l", "c"},
+			set:   []string{"a", "b", "c"},
+			merge: []string{"x", "a", "b", "c"},
+			apply: true,
+		},
+		{
+			name:  "flag is not set",
+			args:  []string{},
+			set:   []string{"x"},
+			merge: []string{"x"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := NewFlagSet("")
+			val := TypedVarP[[]string](fs, &listValue{}, "list", "l", "", nil)
+			if err := fs.Parse(c.args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			set := []string{"x"}
+			val.Set(&set)
+			if !reflect.DeepEqual(set, c.set) {
+				t.Errorf("Set: got %q but expected %q", set, c.set)
+			}
+			merge := []string{"x"}
+			val.Merge(&merge)
+			if !reflect.DeepEqual(merge, c.merge) {
+				t.Errorf("Merge: got %q but expected %q", merge, c.merge)
+			}
+			applied := false
+			val.Apply(func(value []string) {
+				applied = true
+				if !reflect.DeepEqual(value, c.set) {
+					t.Errorf("Apply: got %q but expected %q", value, c.set)
+				}
+			})
+			if applied != c.apply {
+				t.Errorf("Apply: got applied %t but expected %t", applied, c.apply)
+			}
+		})
+	}
+}
+
+func TestTypedVarGetter(t *testing.T) {
+	fs := NewFlagSet("")
+	val := TypedVar[map[string]string](fs, &labelsValue{}, "labels", "", nil)
+	if err := fs.Parse([]string{"--labels=a=1", "--labels=b=2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	merge := map[string]string{"a": "0", "c": "3"}
+	val.Merge(&merge)
+	if expect := map[string]string{"a": "1", "b": "2", "c": "3"}; !reflect.DeepEqual(merge, expect) {
+		t.Errorf("Merge: got %v but expected %v", merge, expect)
+	}
+	var nilMap map[string]string
+	val.Merge(&nilMap)
+	if expect := map[string]string{"a": "1", "b": "2"}; !reflect.DeepEqual(nilMap, expect) {
+		t.Errorf("Merge: got %v but expected %v", nilMap, expect)
+	}
+}
+
+func TestTypedVarExtractAndMergeFunc(t *testing.T) {
+	fs := NewFlagSet("")
+	count := func(v pflag.Value) int { return len(*v.(*listValue)) }
+	val := TypedVar(fs, &listValue{}, "list", "", count).MergeFunc(func(target *int, value int) {
+		*target += value
+	})
+	if err := fs.Parse([]string{"--list=a,b,c"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n := 1
+	val.Merge(&n)
+	if n != 4 {
+		t.Errorf("Merge: got %d but expected 4", n)
+	}
+	val.Set(&n)
+	if n != 3 {
+		t.Errorf("Set: got %d but expected 3", n)
+	}
+}
+
+func TestTypedVarTypeMismatch(t *testing.T) {
+	defer func() {
+		expect := "legacyflag: flag value of type legacyflag.listValue can't be converted to string"
+		if r := recover(); r != expect {
+			t.Errorf("expected panic %q but got %v", expect, r)
+		}
+	}()
+	TypedVar[string](NewFlagSet(""), &listValue{}, "list", "", nil)
+}
+
+// nilGetter is a Getter whose Get returns untyped nil.
+type nilGetter struct{ listValue }
+
+func (*nilGetter) Get() interface{} { return nil }
+
+func TestTypedVarNilValue(t *testing.T) {
+	fs := NewFlagSet("")
+	val := TypedVar[[]string](fs, &nilGetter{}, "list", "", nil)
+	if err := fs.Parse([]string{"--list=a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	set := []string{"x"}
+	val.Set(&set)
+	if set != nil {
+		t.Errorf("Set: got %q but expected nil", set)
+	}
+	merge := []string{"x"}
+	val.Merge(&merge)
+	if !reflect.DeepEqual(merge, []string{"x"}) {
+		t.Errorf("Merge: got %q but expected %q", merge, []string{"x"})
+	}
+	applied := false
+	val.Apply(func(value []string) { applied = value == nil })
+	if !applied {
+		t.Errorf("Apply: expected to be called with a nil slice")
+	}
+}
+
+func TestTypedVarGoType(t *testing.T) {
+	fs := NewFlagSet("")
+	val := TypedVar[map[string]string](fs, &labelsValue{}, "labels", "", nil)
+	if got := goType(val, fs.fs.Lookup("labels").Value); got != "map[string]string" {
+		t.Errorf("got Go type %q but expected map[string]string", got)
+	}
+}
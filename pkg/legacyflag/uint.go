@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,36 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
+import (
 	"github.com/spf13/pflag"
-	
 )
 
 // UintValue is a reference to a registered uint flag value.
 type UintValue struct {
-	name string
+	name  string
 	value uint
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // UintVar registers a flag for uint against the FlagSet, and returns
 // a UintValue reference to the registered flag value.
 func (fs *FlagSet) UintVar(name string, def uint, usage string) *UintValue {
+	return fs.UintVarP(name, "", def, usage)
+}
+
+// UintVarP is like UintVar, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) UintVarP(name, shorthand string, def uint, usage string) *UintValue {
 	v := &UintValue{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.UintVar(&v.value, name, def, usage)
+	fs.fs.UintVarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -54,3 +60,8 @@ func (v *UintValue) Apply(apply func(value uint)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *UintValue) goType() string {
+	return "uint"
+}
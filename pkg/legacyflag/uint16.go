@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,36 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
+import (
 	"github.com/spf13/pflag"
-	
 )
 
 // Uint16Value is a reference to a registered uint16 flag value.
 type Uint16Value struct {
-	name string
+	name  string
 	value uint16
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // Uint16Var registers a flag for uint16 against the FlagSet, and returns
 // a Uint16Value reference to the registered flag value.
 func (fs *FlagSet) Uint16Var(name string, def uint16, usage string) *Uint16Value {
+	return fs.Uint16VarP(name, "", def, usage)
+}
+
+// Uint16VarP is like Uint16Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) Uint16VarP(name, shorthand string, def uint16, usage string) *Uint16Value {
 	v := &Uint16Value{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.Uint16Var(&v.value, name, def, usage)
+	fs.fs.Uint16VarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -54,3 +60,8 @@ func (v *Uint16Value) Apply(apply func(value uint16)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *Uint16Value) goType() string {
+	return "uint16"
+}
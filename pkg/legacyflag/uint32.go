@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,36 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
+import (
 	"github.com/spf13/pflag"
-	
 )
 
 // Uint32Value is a reference to a registered uint32 flag value.
 type Uint32Value struct {
-	name string
+	name  string
 	value uint32
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // Uint32Var registers a flag for uint32 against the FlagSet, and returns
 // a Uint32Value reference to the registered flag value.
 func (fs *FlagSet) Uint32Var(name string, def uint32, usage string) *Uint32Value {
+	return fs.Uint32VarP(name, "", def, usage)
+}
+
+// Uint32VarP is like Uint32Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) Uint32VarP(name, shorthand string, def uint32, usage string) *Uint32Value {
 	v := &Uint32Value{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.Uint32Var(&v.value, name, def, usage)
+	fs.fs.Uint32VarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -54,3 +60,8 @@ func (v *Uint32Value) Apply(apply func(value uint32)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *Uint32Value) goType() string {
+	return "uint32"
+}
@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-	http://www.apache.org/licenses/LICENSE-2.0
+    http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,30 +14,36 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file is generated. DO NOT EDIT.
+// Code generated by legacyflag-gen. DO NOT EDIT.
 
 package legacyflag
 
-import(
+import (
 	"github.com/spf13/pflag"
-	
 )
 
 // Uint64Value is a reference to a registered uint64 flag value.
 type Uint64Value struct {
-	name string
+	name  string
 	value uint64
-	fs *pflag.FlagSet
+	fs    *pflag.FlagSet
 }
 
 // Uint64Var registers a flag for uint64 against the FlagSet, and returns
 // a Uint64Value reference to the registered flag value.
 func (fs *FlagSet) Uint64Var(name string, def uint64, usage string) *Uint64Value {
+	return fs.Uint64VarP(name, "", def, usage)
+}
+
+// Uint64VarP is like Uint64Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func (fs *FlagSet) Uint64VarP(name, shorthand string, def uint64, usage string) *Uint64Value {
 	v := &Uint64Value{
 		name: name,
-		fs: fs.fs,
+		fs:   fs.fs,
 	}
-	fs.fs.Uint64Var(&v.value, name, def, usage)
+	fs.fs.Uint64VarP(&v.value, name, shorthand, def, usage)
+	fs.register(name, v)
 	return v
 }
 
@@ -54,3 +60,8 @@ func (v *Uint64Value) Apply(apply func(value uint64)) {
 		apply(v.value)
 	}
 }
+
+// goType implements goTyper.
+func (v *Uint64Value) goType() string {
+	return "uint64"
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// defaultSection is the title of the usage section for flags that were
+	// not assigned to a section.
+	defaultSection = "Flags"
+	// deprecatedSection is the title of the usage section for deprecated flags.
+	deprecatedSection = "Deprecated"
+)
+
+// Usage writes the usage of the non-hidden flags, grouped by section, to w.
+// Experimental flags are marked as such.
+// Flags that were not assigned to a section are listed first, followed by the
+// sections in declared order. Deprecated flags are listed last, in their own
+// section. Lines are wrapped at width columns, or not at all if width is 0.
+func (fs *FlagSet) Usage(w io.Writer, width int) error {
+	return fs.UsageFor(w, width, fs.fs)
+}
+
+// UsageFor writes the usage of the non-hidden flags in flags to w, like
+// Usage, using the sections and metadata recorded in fs. This is useful when
+// the flags of fs were added to another pflag.FlagSet, e.g. by a command line
+// framework, along with other flags. Flags that are unknown to fs are listed in
+// the default section.
+func (fs *FlagSet) UsageFor(w io.Writer, width int, flags *pflag.FlagSet) error {
+	sets := map[string]*pflag.FlagSet{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		// render a copy, so that we don't modify the registered flag
+		c := *f
+		m := fs.flagMetadata(f.Name)
+		title := m.section()
+		switch {
+		case m.visibility() >= VisibilityHidden:
+			// deprecated flags are hidden in pflag, so only the recorded
+			// visibility tells whether they should be listed
+			return
+		case f.Deprecated != "":
+			title = deprecatedSection
+			c.Usage = fmt.Sprintf("%s (DEPRECATED: %s)", c.Usage, c.Deprecated)
+			c.Deprecated = ""
+			c.Hidden = false
+		case f.Hidden:
+			return
+		}
+		if m.visibility() == VisibilityExperimental {
+			c.Usage = fmt.Sprintf("%s (EXPERIMENTAL)", c.Usage)
+		}
+		if field := m.configField(); field != "" {
+			c.Usage = fmt.Sprintf("%s (config file field: %s)", c.Usage, field)
+		}
+		if sets[title] == nil {
+			sets[title] = pflag.NewFlagSet(title, pflag.ContinueOnError)
+		}
+		sets[title].AddFlag(&c)
+	})
+
+	b := &bytes.Buffer{}
+	titles := append(append([]string{defaultSection}, fs.sections...), deprecatedSection)
+	for _, title := range titles {
+		set, ok := sets[title]
+		if !ok {
+			continue
+		}
+		// only render each section once, even if it was declared with a reserved title
+		delete(sets, title)
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "%s:\n%s", title, set.FlagUsagesWrapped(width))
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// section returns the usage section title for m, which may be nil.
+func (m *Metadata) section() string {
+	if m == nil || m.Section == "" {
+		return defaultSection
+	}
+	return m.Section
+}
+
+// visibility returns the visibility level for m, which may be nil.
+func (m *Metadata) visibility() Visibility {
+	if m == nil {
+		return VisibilityPublic
+	}
+	return m.Visibility
+}
+
+// configField returns the config field for m, which may be nil.
+func (m *Metadata) configField() string {
+	if m == nil {
+		return ""
+	}
+	return m.ConfigField
+}
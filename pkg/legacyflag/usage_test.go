@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestUsage(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.StringVar("name", "", "The name.")
+	networking := fs.Section("Networking")
+	fs.Section("Empty")
+	networking.IntVar("port", 80, "The port.")
+	networking.StringVar("address", "0.0.0.0", "The address.")
+	fs.Section("Storage").StringVar("root-dir", "/var/lib", "The root directory.")
+	fs.BoolVar("old", false, "An old flag.")
+	fs.BoolVar("hidden", false, "A hidden flag.")
+	for _, err := range []error{
+		fs.MarkConfigField("port", "Port"),
+		fs.MarkDeprecated("old", "Use --new instead."),
+		fs.fs.MarkHidden("hidden"),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expect := `Flags:
+      --name string   The name.
+
+Networking:
+      --address string   The address. (default "0.0.0.0")
+      --port int         The port. (config file field: Port) (default 80)
+
+Storage:
+      --root-dir string   The root directory. (default "/var/lib")
+
+Deprecated:
+      --old   An old flag. (DEPRECATED: Use --new instead.)
+`
+	b := &bytes.Buffer{}
+	if err := fs.Usage(b, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.String() != expect {
+		t.Errorf("got:\n%s\nexpected:\n%s", b.String(), expect)
+	}
+
+	// rendering must not modify the registered flags
+	if f := fs.fs.Lookup("old"); f.Deprecated == "" || !f.Hidden {
+		t.Errorf("Usage modified deprecated flag: %#v", f)
+	}
+	if f := fs.fs.Lookup("port"); f.Usage != "The port." {
+		t.Errorf("Usage modified flag usage: %q", f.Usage)
+	}
+}
+
+func TestMarkSection(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.StringVar("foo", "", "")
+	fs.Section("Bar").StringVar("bar", "", "")
+	if err := fs.MarkSection("foo", "Foo"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := fs.Metadata("foo").Section; s != "Foo" {
+		t.Errorf("got section %q but expected %q", s, "Foo")
+	}
+	if s := fs.Metadata("bar").Section; s != "Bar" {
+		t.Errorf("got section %q but expected %q", s, "Bar")
+	}
+	if err := fs.MarkSection("missing", "Foo"); err == nil {
+		t.Errorf("expect error for unknown flag")
+	}
+
+	// sections are declared in order of first use
+	expect := []string{"Bar", "Foo"}
+	if !reflect.DeepEqual(fs.sections, expect) {
+		t.Errorf("got sections %v but expected %v", fs.sections, expect)
+	}
+}
+
+func TestUsageFor(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.Section("Networking").IntVar("port", 80, "The port.")
+	fs.StringVar("name", "", "The name.")
+
+	// e.g. a framework that adds its own flags
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.AddFlagSet(fs.PflagFlagSet())
+	flags.BoolP("help", "h", false, "Help.")
+
+	expect := `Flags:
+  -h, --help          Help.
+      --name string   The name.
+
+Networking:
+      --port int   The port. (default 80)
+`
+	b := &bytes.Buffer{}
+	if err := fs.UsageFor(b, 0, flags); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.String() != expect {
+		t.Errorf("got:\n%s\nexpected:\n%s", b.String(), expect)
+	}
+}
+
+func TestUsageDeprecatedVisibility(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.WithVisibility(VisibilityExperimental).IntVar("port", 80, "The port.")
+	fs.WithVisibility(VisibilityInternal).BoolVar("test-only", false, "A test flag.")
+	fs.WithVisibility(VisibilityHidden).BoolVar("old-hidden", false, "An old hidden flag.")
+	for _, err := range []error{
+		fs.MarkConfigField("port", "Port"),
+		fs.Alias("old-port", "port", ""),
+		fs.Alias("old-test-only", "test-only", ""),
+		fs.MarkDeprecated("old-hidden", "Use --port instead."),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expect := `Flags:
+      --port int   The port. (EXPERIMENTAL) (config file field: Port) (default 80)
+
+Deprecated:
+      --old-port int   The port. (DEPRECATED: ` + deprecated + `) (EXPERIMENTAL) (config file field: Port) (default 80)
+`
+	b := &bytes.Buffer{}
+	if err := fs.Usage(b, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.String() != expect {
+		t.Errorf("got:\n%s\nexpected:\n%s", b.String(), expect)
+	}
+}
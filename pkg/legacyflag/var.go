@@ -29,11 +29,18 @@ type VarValue struct {
 // Var registers a flag for a type that implements the pflag.Value interface
 // against the FlagSet, and returns a VarValue that references this flag.
 func (fs *FlagSet) Var(value pflag.Value, name string, usage string) *VarValue {
+	return fs.VarP(value, name, "", usage)
+}
+
+// VarP is like Var, but accepts a shorthand letter that can be used after a
+// single dash.
+func (fs *FlagSet) VarP(value pflag.Value, name, shorthand string, usage string) *VarValue {
 	v := &VarValue{
 		name: name,
 		fs:   fs.fs,
 	}
-	fs.fs.Var(value, name, usage)
+	fs.fs.VarP(value, name, shorthand, usage)
+	fs.register(name, v)
 	return v
 }
 
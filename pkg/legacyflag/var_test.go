@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVarP(t *testing.T) {
+	for _, args := range [][]string{{"--foo=bar"}, {"-f", "bar"}} {
+		var scratch testValue
+		fs := NewFlagSet("")
+		val := fs.VarP(&scratch, "foo", "f", "")
+		if err := fs.Parse(args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		applied := false
+		val.Apply(func() { applied = true })
+		if !applied || scratch.s != "bar" {
+			t.Errorf("%q: got applied %t with value %q", args, applied, scratch.s)
+		}
+	}
+}
+
+func TestMarkShorthandDeprecated(t *testing.T) {
+	fs := NewFlagSet("")
+	val := fs.BoolVarP("verbose", "v", false, "Be verbose.")
+	if err := fs.MarkShorthandDeprecated("verbose", "use --verbose instead"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := &bytes.Buffer{}
+	fs.PflagFlagSet().SetOutput(out)
+	if err := fs.Parse([]string{"-v"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	verbose := false
+	val.Set(&verbose)
+	if !verbose {
+		t.Errorf("expected the deprecated shorthand to set the flag")
+	}
+	if expect := "Flag shorthand -v has been deprecated, use --verbose instead\n"; out.String() != expect {
+		t.Errorf("got warning %q but expected %q", out.String(), expect)
+	}
+
+	usage := &bytes.Buffer{}
+	if err := fs.Usage(usage, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expect := "Flags:\n      --verbose   Be verbose.\n"; usage.String() != expect {
+		t.Errorf("got usage %q but expected %q", usage.String(), expect)
+	}
+	if err := fs.MarkShorthandDeprecated("missing", "gone"); err == nil {
+		t.Errorf("expected error for missing flag")
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Visibility determines where a flag is shown.
+type Visibility int
+
+const (
+	// VisibilityPublic flags are shown in usage and documentation.
+	VisibilityPublic Visibility = iota
+	// VisibilityExperimental flags are shown in usage and documentation, marked
+	// as experimental. See GateExperimental.
+	VisibilityExperimental
+	// VisibilityHidden flags are not shown in usage, but are documented.
+	VisibilityHidden
+	// VisibilityInternal flags are neither shown in usage nor documented, e.g.
+	// flags intended for tests.
+	VisibilityInternal
+)
+
+var visibilityNames = []string{"public", "experimental", "hidden", "internal"}
+
+// String returns the lower case name of the visibility level.
+func (v Visibility) String() string {
+	if v < 0 || int(v) >= len(visibilityNames) {
+		return fmt.Sprintf("Visibility(%d)", int(v))
+	}
+	return visibilityNames[v]
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (v Visibility) MarshalText() ([]byte, error) {
+	if v < 0 || int(v) >= len(visibilityNames) {
+		return nil, fmt.Errorf("invalid visibility %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (v *Visibility) UnmarshalText(text []byte) error {
+	for i, name := range visibilityNames {
+		if string(text) == name {
+			*v = Visibility(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid visibility %q", string(text))
+}
+
+// WithVisibility returns a view of the FlagSet that registers flags with the
+// given visibility level.
+func (fs *FlagSet) WithVisibility(v Visibility) *FlagSet {
+	return &FlagSet{
+		fs:         fs.fs,
+		section:    fs.section,
+		visibility: v,
+		state:      fs.state,
+	}
+}
+
+// MarkVisibility sets the visibility level of the named flag. Hidden and
+// internal flags are also hidden in the underlying pflag.FlagSet. Deprecated
+// flags stay hidden there whatever their visibility, as pflag hides them.
+func (fs *FlagSet) MarkVisibility(name string, v Visibility) error {
+	m, err := fs.metadata(name)
+	if err != nil {
+		return err
+	}
+	m.Visibility = v
+	f := fs.fs.Lookup(name)
+	f.Hidden = v >= VisibilityHidden || f.Deprecated != ""
+	return nil
+}
+
+// MarkHidden hides the named flag from usage.
+func (fs *FlagSet) MarkHidden(name string) error {
+	return fs.MarkVisibility(name, VisibilityHidden)
+}
+
+// experimentalGate rejects experimental flags unless they are allowed.
+type experimentalGate struct {
+	// allow is the value of the opt-in flag, if any.
+	allow *BoolValue
+	// flag is the name of the opt-in flag, if any.
+	flag string
+	// enabled reports whether experimental flags are enabled otherwise, e.g.
+	// by a feature gate.
+	enabled func() bool
+}
+
+// GateExperimental makes Validate reject experimental flags unless they are
+// allowed. If flagName is not empty, a bool flag with that name is registered
+// that allows experimental flags, e.g. "allow-experimental". If enabled is
+// not nil, experimental flags are also allowed when it returns true, e.g. to
+// check a feature gate.
+func (fs *FlagSet) GateExperimental(flagName string, enabled func() bool) {
+	g := &experimentalGate{flag: flagName, enabled: enabled}
+	if flagName != "" {
+		g.allow = fs.WithVisibility(VisibilityPublic).BoolVar(flagName, false, "Allow the use of experimental flags.")
+	}
+	fs.experimentalGate = g
+}
+
+// checkExperimental returns an error if an experimental flag was set but is
+// not allowed.
+func (fs *FlagSet) checkExperimental() error {
+	g := fs.experimentalGate
+	if g == nil {
+		return nil
+	}
+	allowed := false
+	if g.allow != nil {
+		g.allow.Set(&allowed)
+	}
+	if allowed || (g.enabled != nil && g.enabled()) {
+		return nil
+	}
+	names := make([]string, 0, len(fs.meta))
+	for name := range fs.meta {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if fs.meta[name].Visibility != VisibilityExperimental || !fs.fs.Changed(name) {
+			continue
+		}
+		if g.flag != "" {
+			return fmt.Errorf("flag --%s is experimental, set --%s to use it", name, g.flag)
+		}
+		return fmt.Errorf("flag --%s is experimental, and experimental flags are not enabled", name)
+	}
+	return nil
+}
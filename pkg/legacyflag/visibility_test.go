@@ -0,0 +1,161 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacyflag
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestVisibilityUsage(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.StringVar("name", "", "The name.")
+	fs.WithVisibility(VisibilityExperimental).BoolVar("new-thing", false, "A new thing.")
+	fs.Section("Debugging").WithVisibility(VisibilityExperimental).BoolVar("trace", false, "Trace requests.")
+	fs.WithVisibility(VisibilityInternal).BoolVar("testing-only", false, "For tests.")
+	fs.BoolVar("secret", false, "A hidden flag.")
+	if err := fs.MarkHidden("secret"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expect := `Flags:
+      --name string   The name.
+      --new-thing     A new thing. (EXPERIMENTAL)
+
+Debugging:
+      --trace   Trace requests. (EXPERIMENTAL)
+`
+	b := &bytes.Buffer{}
+	if err := fs.Usage(b, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.String() != expect {
+		t.Errorf("got:\n%s\nexpected:\n%s", b.String(), expect)
+	}
+	for name, v := range map[string]Visibility{
+		"name":         VisibilityPublic,
+		"trace":        VisibilityExperimental,
+		"testing-only": VisibilityInternal,
+		"secret":       VisibilityHidden,
+	} {
+		if got := fs.Metadata(name).Visibility; got != v {
+			t.Errorf("%s: got visibility %s but expected %s", name, got, v)
+		}
+	}
+	if !fs.fs.Lookup("testing-only").Hidden {
+		t.Errorf("expected internal flag to be hidden in the pflag.FlagSet")
+	}
+
+	// deprecated flags stay hidden in the pflag.FlagSet
+	fs.StringVar("old", "", "")
+	if err := fs.MarkDeprecated("old", "use --name"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.MarkVisibility("old", VisibilityPublic); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u := fs.fs.FlagUsages(); strings.Contains(u, "--old") {
+		t.Errorf("expected deprecated flag to be hidden, got usage:\n%s", u)
+	}
+	if err := fs.MarkHidden("missing"); err == nil {
+		t.Errorf("expected error for missing flag")
+	}
+}
+
+func TestGateExperimental(t *testing.T) {
+	cases := []struct {
+		name     string
+		flagName string
+		enabled  bool
+		args     []string
+		err      string
+	}{
+		{
+			name:     "experimental flag not set",
+			flagName: "allow-experimental",
+			args:     []string{"--stable"},
+		},
+		{
+			name:     "experimental flag rejected",
+			flagName: "allow-experimental",
+			args:     []string{"--new-thing"},
+			err:      "flag --new-thing is experimental, set --allow-experimental to use it",
+		},
+		{
+			name:     "experimental flag allowed by flag",
+			flagName: "allow-experimental",
+			args:     []string{"--new-thing", "--allow-experimental"},
+		},
+		{
+			name:    "experimental flag allowed by gate",
+			enabled: true,
+			args:    []string{"--new-thing"},
+		},
+		{
+			name: "experimental flag rejected by gate",
+			args: []string{"--new-thing"},
+			err:  "flag --new-thing is experimental, and experimental flags are not enabled",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := NewFlagSet("")
+			fs.BoolVar("stable", false, "")
+			fs.WithVisibility(VisibilityExperimental).BoolVar("new-thing", false, "")
+			fs.GateExperimental(c.flagName, func() bool { return c.enabled })
+
+			err := fs.Parse(c.args)
+			if c.err == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if c.err != "" && (err == nil || err.Error() != c.err) {
+				t.Errorf("expected error %q but got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestVisibilitySchema(t *testing.T) {
+	fs := NewFlagSet("")
+	fs.WithVisibility(VisibilityExperimental).BoolVar("new-thing", false, "")
+	fs.WithVisibility(VisibilityInternal).BoolVar("testing-only", false, "")
+	b, err := fs.Schema()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := &Schema{}
+	if err := json.Unmarshal(b, s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	restored, err := NewFromSchema(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := restored.Metadata("new-thing").Visibility; v != VisibilityExperimental {
+		t.Errorf("got visibility %s but expected experimental", v)
+	}
+	if v := restored.Metadata("testing-only").Visibility; v != VisibilityInternal {
+		t.Errorf("got visibility %s but expected internal", v)
+	}
+
+	v := Visibility(0)
+	if err := json.Unmarshal([]byte(`"unknown"`), &v); err == nil {
+		t.Errorf("expected error for unknown visibility")
+	}
+}